@@ -2,46 +2,59 @@ package main
 
 import (
 	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/util"
+	"github.com/pkg/errors"
 	"strings"
 )
 
 func main() {
 	fmt.Println("Advent of Code 2017 - day 07")
 
-	programTree := ParseInput(input)
+	programTree, err := ParseInput(input)
+	if err != nil {
+		panic(err)
+	}
 
 	fmt.Printf("The bottom program is %q\n", programTree.Name)
 
-	unbalancedProgram := FindTopMostUnbalancedProgram(programTree)
-
-	fmt.Printf("Unbalanced program is %q, which holds:\n", unbalancedProgram.Name)
-	for _, p := range unbalancedProgram.Holds {
-		fmt.Printf("- %q (%v), total weight = %v\n", p.Name, p.Weight, p.RecursiveWeight())
+	diagnosis, err := Diagnose(programTree)
+	if err != nil {
+		panic(err)
 	}
+
+	fmt.Printf("Program %q weighs %v, but should weigh %v\n", diagnosis.Program.Name, diagnosis.Weight, diagnosis.CorrectWeight)
+	fmt.Printf("Path from the bottom: %v\n", strings.Join(diagnosis.Path, " -> "))
 }
 
 type Program struct {
 	Name       string
 	Weight     int
 	HoldsNames []string
-	Holds      []Program
+	Holds      []*Program
+
+	// the recursive weight, once it has been calculated
+	recursiveWeight int
+	weighed         bool
 }
 
-func (p Program) String() string {
+func (p *Program) String() string {
 	return fmt.Sprintf("%q (%v) holds %v", p.Name, p.Weight, p.HoldsNames)
 }
 
-func (p Program) RecursiveWeight() int {
-	weight := p.Weight
-
-	for _, programHeld := range p.Holds {
-		weight += programHeld.RecursiveWeight()
+// RecursiveWeight returns the weight of the program and all programs it holds. It is calculated once, towers from
+// ParseInput have it calculated already.
+func (p *Program) RecursiveWeight() int {
+	if !p.weighed {
+		p.recursiveWeight = p.Weight
+		for _, programHeld := range p.Holds {
+			p.recursiveWeight += programHeld.RecursiveWeight()
+		}
+		p.weighed = true
 	}
-
-	return weight
+	return p.recursiveWeight
 }
 
-func (p Program) IsBalanced() bool {
+func (p *Program) IsBalanced() bool {
 	if len(p.Holds) == 0 {
 		return true
 	}
@@ -56,7 +69,7 @@ func (p Program) IsBalanced() bool {
 	return true
 }
 
-func FindTopMostUnbalancedProgram(programTree Program) Program {
+func FindTopMostUnbalancedProgram(programTree *Program) *Program {
 	for _, p := range programTree.Holds {
 		if !p.IsBalanced() {
 			return FindTopMostUnbalancedProgram(p)
@@ -65,70 +78,314 @@ func FindTopMostUnbalancedProgram(programTree Program) Program {
 	return programTree
 }
 
-func ParseProgram(line string) Program {
-	var p Program
+// Diagnosis describes the program that has the wrong weight.
+type Diagnosis struct {
+	Program       *Program
+	Weight        int
+	CorrectWeight int
+	// the names of the programs from the bottom program up to Program, including both
+	Path []string
+}
+
+// Diagnose finds the single program that has to change its weight to balance the tower. It returns an error if the
+// tower is balanced already, or if the wrong program can not be determined with certainty: when the bottom program
+// holds two programs with a different weight, or a program holds more than two different weights. It also returns
+// an error if changing a single program can not balance the tower.
+func Diagnose(tower *Program) (d Diagnosis, err error) {
+	odd, correctWeight, err := findOddProgram(tower, 0)
+	if err != nil {
+		return
+	}
+	if odd < 0 {
+		return d, errors.New(fmt.Sprintf("the tower on %q is balanced", tower.Name))
+	}
+
+	p := tower.Holds[odd]
+	d.Path = []string{tower.Name, p.Name}
+
+	// the program has to change the total weight it carries by delta
+	delta := correctWeight - p.RecursiveWeight()
+
+	for {
+		odd, correctWeight, err = findOddProgram(p, delta)
+		if err != nil {
+			return
+		}
+		if odd < 0 {
+			break
+		}
+
+		// fixing the odd program has to fix the program holding it as well
+		held := p.Holds[odd]
+		if correctWeight-held.RecursiveWeight() != delta {
+			return d, errors.New(fmt.Sprintf("program %q has to change by %v, but %q, which it holds, by %v: no single program can be fixed", p.Name, delta, held.Name, correctWeight-held.RecursiveWeight()))
+		}
+
+		p = held
+		d.Path = append(d.Path, p.Name)
+	}
+
+	d.Program = p
+	d.Weight = p.Weight
+	d.CorrectWeight = p.Weight + delta
+
+	if d.CorrectWeight < 0 {
+		return d, errors.New(fmt.Sprintf("program %q would need a negative weight of %v", d.Program.Name, d.CorrectWeight))
+	}
+	return
+}
+
+// findOddProgram returns the index of the program held by p with a different recursive weight than the others and
+// the weight it should have, or -1 if they all weigh the same. Delta is the change p's recursive weight needs, or 0
+// if it is not known, it decides which of two held programs is wrong.
+func findOddProgram(p *Program, delta int) (odd int, correctWeight int, err error) {
+	count := make(map[int]int)
+	for _, held := range p.Holds {
+		count[held.RecursiveWeight()] += 1
+	}
+
+	switch {
+	case len(count) <= 1:
+		return -1, 0, nil
+	case len(count) > 2:
+		return -1, 0, errors.New(fmt.Sprintf("program %q holds programs with %v different weights", p.Name, len(count)))
+	case len(p.Holds) == 2 && delta != 0:
+		return pickOddOfTwo(p, delta)
+	case len(p.Holds) == 2:
+		return -1, 0, errors.New(fmt.Sprintf("program %q holds %q and %q with different weights, either could be wrong", p.Name, p.Holds[0].Name, p.Holds[1].Name))
+	}
+
+	for weight, n := range count {
+		if n > 1 {
+			correctWeight = weight
+		}
+	}
+	for i, held := range p.Holds {
+		if held.RecursiveWeight() != correctWeight {
+			return i, correctWeight, nil
+		}
+	}
+	panic("unreachable")
+}
+
+// pickOddOfTwo returns the index of the program held by p that balances p when its recursive weight changes by delta
+func pickOddOfTwo(p *Program, delta int) (odd int, correctWeight int, err error) {
+	odd = -1
+	for i, held := range p.Holds {
+		sibling := p.Holds[1-i]
+		if held.RecursiveWeight()+delta != sibling.RecursiveWeight() {
+			continue
+		}
+		if odd >= 0 {
+			return -1, 0, errors.New(fmt.Sprintf("program %q holds %q and %q, changing either by %v balances it", p.Name, p.Holds[0].Name, p.Holds[1].Name, delta))
+		}
+		odd, correctWeight = i, sibling.RecursiveWeight()
+	}
+	if odd < 0 {
+		return -1, 0, errors.New(fmt.Sprintf("program %q holds %q and %q, changing neither by %v balances it", p.Name, p.Holds[0].Name, p.Holds[1].Name, delta))
+	}
+	return
+}
 
-	_, err := fmt.Sscanf(line, "%s (%d)", &p.Name, &p.Weight)
+func ParseProgram(line string) (p Program, err error) {
+	_, err = fmt.Sscanf(line, "%s (%d)", &p.Name, &p.Weight)
 	if err != nil {
-		panic(fmt.Sprintf("could not parse input %q", line))
+		return p, errors.New(fmt.Sprintf("could not parse input %q", line))
 	}
 
 	indexProgramsAbove := strings.Index(line, "->")
 	if indexProgramsAbove > 0 {
-		p.HoldsNames = strings.Split(line[indexProgramsAbove+3:], ", ")
+		for _, name := range strings.Split(line[indexProgramsAbove+2:], ",") {
+			p.HoldsNames = append(p.HoldsNames, strings.TrimSpace(name))
+		}
 	}
 
-	return p
+	return p, nil
 }
 
-func ParseInput(input string) Program {
-	lines := strings.Split(input, "\n")
+// ParseInput builds the tower of programs, blank lines are skipped. If the programs do not form a single tower, the
+// error is a TowerErrors listing every line that could not be parsed, duplicate names, programs that are held but
+// not declared or held more than once, multiple bottom programs and cycles.
+func ParseInput(input string) (*Program, error) {
+	var towerErrors TowerErrors
 
-	programs := make(map[string]Program, len(lines))
+	var programs []Program
+	var lines []int
+	index := make(map[string]int)
 
-	for _, line := range lines {
-		program := ParseProgram(line)
-		programs[program.Name] = program
+	for i, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		program, err := ParseProgram(line)
+		if err != nil {
+			towerErrors = append(towerErrors, util.LineError{Line: i + 1, Err: err})
+			continue
+		}
+
+		if first, ok := index[program.Name]; ok {
+			towerErrors = append(towerErrors, DuplicateNameError{program.Name, []int{lines[first], i + 1}})
+			continue
+		}
+		index[program.Name] = len(programs)
+		programs = append(programs, program)
+		lines = append(lines, i+1)
 	}
 
-	bottomProgram := findBottomProgram(programs)
+	parents := parentIndex(programs, index, lines, &towerErrors)
 
-	linkPrograms(&bottomProgram, programs)
+	var roots []int
+	for i := range programs {
+		if parents[i] < 0 {
+			roots = append(roots, i)
+		}
+	}
+	if len(roots) > 1 {
+		err := MultipleRootsError{}
+		for _, r := range roots {
+			err.Names = append(err.Names, programs[r].Name)
+			err.Lines = append(err.Lines, lines[r])
+		}
+		towerErrors = append(towerErrors, err)
+	}
+
+	for _, cycle := range findCycles(programs, index) {
+		err := CycleError{}
+		for _, i := range cycle {
+			err.Names = append(err.Names, programs[i].Name)
+			err.Lines = append(err.Lines, lines[i])
+		}
+		towerErrors = append(towerErrors, err)
+	}
+
+	if len(towerErrors) > 0 {
+		return nil, towerErrors
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("there are no programs")
+	}
+
+	linkPrograms(programs, index, roots[0])
 
-	return bottomProgram
+	return &programs[roots[0]], nil
 }
 
-func findBottomProgram(programs map[string]Program) Program {
-	for _, candidate := range programs {
-		if !isProgramHeld(programs, candidate.Name) {
-			return candidate
+// parentIndex returns the index of the program holding every program, or -1 if it is not held
+func parentIndex(programs []Program, index map[string]int, lines []int, towerErrors *TowerErrors) []int {
+	parents := make([]int, len(programs))
+	for i := range parents {
+		parents[i] = -1
+	}
+	heldTwice := make(map[int]*HeldTwiceError)
+	var heldTwiceOrder []int
+
+	for i, p := range programs {
+		for _, name := range p.HoldsNames {
+			held, ok := index[name]
+			if !ok {
+				*towerErrors = append(*towerErrors, UndeclaredProgramError{name, p.Name, lines[i]})
+				continue
+			}
+
+			if parents[held] < 0 {
+				parents[held] = i
+				continue
+			}
+
+			err, ok := heldTwice[held]
+			if !ok {
+				first := parents[held]
+				err = &HeldTwiceError{name, []string{programs[first].Name}, []int{lines[first]}}
+				heldTwice[held] = err
+				heldTwiceOrder = append(heldTwiceOrder, held)
+			}
+			err.HeldBy = append(err.HeldBy, p.Name)
+			err.Lines = append(err.Lines, lines[i])
 		}
 	}
-	panic("no bottom program could be found")
+
+	for _, held := range heldTwiceOrder {
+		*towerErrors = append(*towerErrors, *heldTwice[held])
+	}
+	return parents
 }
 
-func isProgramHeld(programs map[string]Program, programName string) bool {
-	for _, p := range programs {
-		for _, programHeld := range p.HoldsNames {
-			if programHeld == programName {
-				return true
+// findCycles returns the cycles of programs holding each other, every cycle starts at the program declared first
+// and every program in it holds the next one
+func findCycles(programs []Program, index map[string]int) (cycles [][]int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(programs))
+	var path []int
+
+	var visit func(p int)
+	visit = func(p int) {
+		state[p] = visiting
+		path = append(path, p)
+
+		for _, name := range programs[p].HoldsNames {
+			held, ok := index[name]
+			if !ok {
+				continue
+			}
+			switch state[held] {
+			case unvisited:
+				visit(held)
+			case visiting:
+				// held is on the path, the cycle is the part from held onwards
+				i := len(path) - 1
+				for path[i] != held {
+					i -= 1
+				}
+				cycles = append(cycles, rotateCycle(path[i:]))
 			}
 		}
+
+		path = path[:len(path)-1]
+		state[p] = visited
+	}
+
+	for p := range programs {
+		if state[p] == unvisited {
+			visit(p)
+		}
+	}
+	return
+}
+
+// rotateCycle returns a copy of the cycle starting at the smallest index
+func rotateCycle(cycle []int) []int {
+	smallest := 0
+	for i, p := range cycle {
+		if p < cycle[smallest] {
+			smallest = i
+		}
 	}
-	return false
+	rotated := append([]int{}, cycle[smallest:]...)
+	return append(rotated, cycle[:smallest]...)
 }
 
-func linkPrograms(p *Program, programs map[string]Program) {
-	for _, heldProgramName := range p.HoldsNames {
-		heldProgram, ok := programs[heldProgramName]
-		if !ok {
-			panic(fmt.Sprintf("program %q could not be found", heldProgramName))
+// linkPrograms fills in the programs every program holds and calculates their recursive weights bottom-up
+func linkPrograms(programs []Program, index map[string]int, root int) {
+	for i := range programs {
+		p := &programs[i]
+		for _, name := range p.HoldsNames {
+			p.Holds = append(p.Holds, &programs[index[name]])
 		}
-		p.Holds = append(p.Holds, heldProgram)
 	}
 
-	for i := 0; i < len(p.Holds); i++ {
-		linkPrograms(&p.Holds[i], programs)
+	// every program comes after the program holding it
+	order := []*Program{&programs[root]}
+	for i := 0; i < len(order); i++ {
+		order = append(order, order[i].Holds...)
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		order[i].RecursiveWeight()
 	}
 }
 
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/util"
+	"github.com/pkg/errors"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -14,7 +19,10 @@ func TestParseProgram(t *testing.T) {
 		{"fwft (72) -> ktlj, cntj, xhth", Program{Name: "fwft", Weight: 72, HoldsNames: []string{"ktlj", "cntj", "xhth"}}},
 	}
 	for _, c := range cases {
-		got := ParseProgram(c.in)
+		got, err := ParseProgram(c.in)
+		if err != nil {
+			t.Errorf("ParseProgram(%q): unexpected error %v", c.in, err)
+		}
 		if !reflect.DeepEqual(got, c.expected) {
 			t.Errorf("ParseProgram(%q) = %v, but expected %v", c.in, got, c.expected)
 		}
@@ -22,15 +30,72 @@ func TestParseProgram(t *testing.T) {
 }
 
 func TestParseInput(t *testing.T) {
-	programTower := loadExampleInput()
+	programTower := loadExampleInput(t)
 
 	if programTower.Name != "tknk" {
 		t.Errorf("found bottom program %v, but expected \"tknk\"", programTower.Name)
 	}
 }
 
+func TestParseInput_Errors(t *testing.T) {
+	input := `a (1) -> b, c
+b (2) -> x
+c (3)
+c (4)
+d (5) -> c
+
+e (6) -> f
+f (7) -> g
+g (8) -> e
+h 9`
+
+	_, err := ParseInput(input)
+
+	expected := TowerErrors{
+		DuplicateNameError{"c", []int{3, 4}},
+		util.LineError{Line: 10, Err: errors.New(fmt.Sprintf("could not parse input %q", "h 9"))},
+		UndeclaredProgramError{"x", "b", 2},
+		HeldTwiceError{"c", []string{"a", "d"}, []int{1, 5}},
+		MultipleRootsError{[]string{"a", "d"}, []int{1, 5}},
+		CycleError{[]string{"e", "f", "g"}, []int{7, 8, 9}},
+	}
+
+	towerErrors, ok := err.(TowerErrors)
+	if !ok || len(towerErrors) != len(expected) {
+		t.Fatalf("expected errors\n%v\nbut got\n%v", expected, err)
+	}
+	for i := range expected {
+		if towerErrors[i].Error() != expected[i].Error() {
+			t.Errorf("expected error %q, but got %q", expected[i], towerErrors[i])
+		}
+	}
+}
+
+func TestParseInput_CycleThroughProgramHeldTwice(t *testing.T) {
+	input := `a (1) -> b
+b (2) -> c
+c (3) -> b`
+
+	_, err := ParseInput(input)
+
+	expected := TowerErrors{
+		HeldTwiceError{"b", []string{"a", "c"}, []int{1, 3}},
+		CycleError{[]string{"b", "c"}, []int{2, 3}},
+	}
+
+	towerErrors, ok := err.(TowerErrors)
+	if !ok || len(towerErrors) != len(expected) {
+		t.Fatalf("expected errors\n%v\nbut got\n%v", expected, err)
+	}
+	for i := range expected {
+		if towerErrors[i].Error() != expected[i].Error() {
+			t.Errorf("expected error %q, but got %q", expected[i], towerErrors[i])
+		}
+	}
+}
+
 func TestProgram_RecursiveWeight(t *testing.T) {
-	programTower := loadExampleInput()
+	programTower := loadExampleInput(t)
 
 	got := programTower.RecursiveWeight()
 	if got != 778 {
@@ -39,7 +104,7 @@ func TestProgram_RecursiveWeight(t *testing.T) {
 }
 
 func TestFindUnbalancedProgram(t *testing.T) {
-	programTower := loadExampleInput()
+	programTower := loadExampleInput(t)
 
 	got := FindTopMostUnbalancedProgram(programTower)
 
@@ -48,7 +113,90 @@ func TestFindUnbalancedProgram(t *testing.T) {
 	}
 }
 
-func loadExampleInput() Program {
+func TestDiagnose(t *testing.T) {
+	programTower := loadExampleInput(t)
+
+	got, err := Diagnose(programTower)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Program.Name != "ugml" || got.Weight != 68 || got.CorrectWeight != 60 {
+		t.Errorf("expected \"ugml\" to weigh 60 instead of 68, but got %q to weigh %v instead of %v", got.Program.Name, got.CorrectWeight, got.Weight)
+	}
+	if expected := []string{"tknk", "ugml"}; !reflect.DeepEqual(got.Path, expected) {
+		t.Errorf("expected path %v, but got %v", expected, got.Path)
+	}
+}
+
+func TestDiagnose_Deeper(t *testing.T) {
+	// d is too heavy by 2, which unbalances b and a
+	input := `a (1) -> b, c, e
+b (1) -> d, f, g
+c (4)
+e (4)
+d (3)
+f (1)
+g (1)`
+
+	got, err := Diagnose(parseTower(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Program.Name != "d" || got.Weight != 3 || got.CorrectWeight != 1 {
+		t.Errorf("expected \"d\" to weigh 1 instead of 3, but got %q to weigh %v instead of %v", got.Program.Name, got.CorrectWeight, got.Weight)
+	}
+	if expected := []string{"a", "b", "d"}; !reflect.DeepEqual(got.Path, expected) {
+		t.Errorf("expected path %v, but got %v", expected, got.Path)
+	}
+}
+
+func TestDiagnose_TwoHeldPrograms(t *testing.T) {
+	// b has to lose 1, only e losing 1 balances b
+	input := `a (1) -> b, c, d
+b (1) -> e, f
+c (3)
+d (3)
+e (2)
+f (1)`
+
+	got, err := Diagnose(parseTower(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Program.Name != "e" || got.Weight != 2 || got.CorrectWeight != 1 {
+		t.Errorf("expected \"e\" to weigh 1 instead of 2, but got %q to weigh %v instead of %v", got.Program.Name, got.CorrectWeight, got.Weight)
+	}
+	if expected := []string{"a", "b", "e"}; !reflect.DeepEqual(got.Path, expected) {
+		t.Errorf("expected path %v, but got %v", expected, got.Path)
+	}
+}
+
+func TestDiagnose_Errors(t *testing.T) {
+	inputs := []string{
+		// balanced
+		"a (1) -> b, c\nb (2)\nc (2)",
+		// either b or c could be wrong
+		"a (1) -> b, c\nb (2)\nc (3)",
+		// three different weights
+		"a (1) -> b, c, d\nb (2)\nc (3)\nd (4)",
+		// b would have to weigh -1
+		"a (1) -> b, c, d\nb (1) -> e\nc (2)\nd (2)\ne (3)",
+		// b has to lose 1, but d is too heavy by 2
+		"a (1) -> b, c, e\nb (1) -> d, f, g\nc (5)\ne (5)\nd (3)\nf (1)\ng (1)",
+		// b has to lose 1, but neither e nor f losing 1 balances b
+		"a (1) -> b, c, d\nb (1) -> e, f\nc (4)\nd (4)\ne (3)\nf (1)",
+	}
+	for _, input := range inputs {
+		if got, err := Diagnose(parseTower(t, input)); err == nil {
+			t.Errorf("expected an error for %q, but got %+v", input, got)
+		}
+	}
+}
+
+func loadExampleInput(t *testing.T) *Program {
 	input := `pbga (66)
 	xhth (57)
 	ebii (61)
@@ -62,6 +210,117 @@ func loadExampleInput() Program {
 	ugml (68) -> gyxo, ebii, jptl
 	gyxo (61)
 	cntj (57)`
-	programTower := ParseInput(input)
+	return parseTower(t, input)
+}
+
+func parseTower(t *testing.T, input string) *Program {
+	programTower, err := ParseInput(input)
+	if err != nil {
+		t.Fatal(err)
+	}
 	return programTower
 }
+
+// generateTower returns a balanced tower of n programs, except for the heavy program that is too heavy by 7. Every
+// program is held by a random program declared before it.
+func generateTower(n int, seed int64) (input string, heavy string) {
+	r := rand.New(rand.NewSource(seed))
+
+	parents := make([]int, n)
+	holds := make([][]int, n)
+	for i := 1; i < n; i++ {
+		parents[i] = r.Intn(i)
+		holds[parents[i]] = append(holds[parents[i]], i)
+	}
+
+	// balance bottom-up by making the programs held by a program heavier
+	weights := make([]int, n)
+	totals := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		weights[i] = 1 + r.Intn(100)
+
+		heaviest := 0
+		for _, h := range holds[i] {
+			if totals[h] > heaviest {
+				heaviest = totals[h]
+			}
+		}
+		for _, h := range holds[i] {
+			weights[h] += heaviest - totals[h]
+			totals[h] = heaviest
+		}
+		totals[i] = weights[i] + heaviest*len(holds[i])
+	}
+
+	// the programs below the heavy program have to hold at least three programs, or another program could be changed
+	// instead
+	h := n - 1
+	for !diagnosable(h, parents, holds) {
+		h -= 1
+	}
+	weights[h] += 7
+
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "p%v (%v)", i, weights[i])
+		for j, h := range holds[i] {
+			if j == 0 {
+				b.WriteString(" -> ")
+			} else {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "p%v", h)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), fmt.Sprintf("p%v", h)
+}
+
+func diagnosable(p int, parents []int, holds [][]int) bool {
+	for ; p != 0; p = parents[p] {
+		if len(holds[parents[p]]) < 3 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiagnose_GeneratedTower(t *testing.T) {
+	input, heavy := generateTower(1000, 1)
+
+	got, err := Diagnose(parseTower(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Program.Name != heavy || got.CorrectWeight != got.Weight-7 {
+		t.Errorf("expected %q to be too heavy by 7, but got %+v", heavy, got)
+	}
+}
+
+func BenchmarkParseInput_100k(b *testing.B) {
+	input, _ := generateTower(100000, 1)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseInput(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDiagnose_100k(b *testing.B) {
+	input, _ := generateTower(100000, 1)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tower, err := ParseInput(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if _, err := Diagnose(tower); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DuplicateNameError struct {
+	Name  string
+	Lines []int
+}
+
+func (e DuplicateNameError) Error() string {
+	return fmt.Sprintf("program %q is declared on lines %v", e.Name, joinInts(e.Lines))
+}
+
+// UndeclaredProgramError is a program held by HeldBy, declared on Line, that is not declared itself.
+type UndeclaredProgramError struct {
+	Name   string
+	HeldBy string
+	Line   int
+}
+
+func (e UndeclaredProgramError) Error() string {
+	return fmt.Sprintf("program %q held by %q on line %v is not declared", e.Name, e.HeldBy, e.Line)
+}
+
+// HeldTwiceError is a program held by more than one program, declared on Lines.
+type HeldTwiceError struct {
+	Name   string
+	HeldBy []string
+	Lines  []int
+}
+
+func (e HeldTwiceError) Error() string {
+	return fmt.Sprintf("program %q is held by %v", e.Name, describe(e.HeldBy, e.Lines))
+}
+
+type MultipleRootsError struct {
+	Names []string
+	Lines []int
+}
+
+func (e MultipleRootsError) Error() string {
+	return fmt.Sprintf("programs %v are not held by any program", describe(e.Names, e.Lines))
+}
+
+// CycleError lists programs that hold each other, every program holds the next one and the last holds the first.
+type CycleError struct {
+	Names []string
+	Lines []int
+}
+
+func (e CycleError) Error() string {
+	return fmt.Sprintf("programs %v hold each other in a cycle", describe(e.Names, e.Lines))
+}
+
+// TowerErrors lists everything that prevents building a tower.
+type TowerErrors []error
+
+func (e TowerErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describe(names []string, lines []int) string {
+	described := make([]string, len(names))
+	for i := range names {
+		described[i] = fmt.Sprintf("%q (line %v)", names[i], lines[i])
+	}
+	return strings.Join(described, ", ")
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = fmt.Sprint(n)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,300 @@
+package instruction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// Range is the interval of values a register can hold, bounds are inclusive. Bounds that would overflow are
+// clamped to the smallest or largest int.
+type Range struct {
+	Min, Max int
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("[%v, %v]", r.Min, r.Max)
+}
+
+// Dependency means the value of register To can depend on register From, either because it is the argument of an
+// operation on To or because it appears in the condition of one.
+type Dependency struct {
+	From, To         string
+	ThroughCondition bool
+}
+
+type Analysis struct {
+	// all dependencies, without registers depending on themselves
+	Dependencies []Dependency
+	// the indices of instructions whose condition can never hold
+	NeverFires []int
+	// the values every register can hold after all instructions are evaluated
+	Ranges map[string]Range
+}
+
+// Analyze evaluates the instructions on the ranges of values registers can hold instead of on their values, starting
+// from an empty RegisterBank. Instructions that can never fire do not introduce dependencies.
+func Analyze(irs []JumpInstruction) (a Analysis) {
+	a.Ranges = make(map[string]Range)
+	dependencies := make(map[Dependency]bool)
+
+	for i, ir := range irs {
+		a.rangeOf(ir.register)
+		a.rangeOfValue(ir.argument)
+		registersOf(ir.condition, func(r string) {
+			a.rangeOf(r)
+		})
+
+		t := a.evaluate(ir.condition)
+		if t == never {
+			a.NeverFires = append(a.NeverFires, i)
+			continue
+		}
+
+		old := a.rangeOf(ir.register)
+		result := apply(ir.operator, old, a.rangeOfValue(ir.argument))
+		if t == maybe {
+			result = join(old, result)
+		}
+		a.Ranges[ir.register] = result
+
+		if r := ir.argument.register; r != "" && r != ir.register {
+			dependencies[Dependency{r, ir.register, false}] = true
+		}
+		registersOf(ir.condition, func(r string) {
+			if r != ir.register {
+				dependencies[Dependency{r, ir.register, true}] = true
+			}
+		})
+	}
+
+	for d := range dependencies {
+		a.Dependencies = append(a.Dependencies, d)
+	}
+	sort.Slice(a.Dependencies, func(i, j int) bool {
+		di, dj := a.Dependencies[i], a.Dependencies[j]
+		if di.To != dj.To {
+			return di.To < dj.To
+		}
+		if di.From != dj.From {
+			return di.From < dj.From
+		}
+		return !di.ThroughCondition && dj.ThroughCondition
+	})
+	return
+}
+
+// Influences returns the registers that can affect the final value of register, directly or through other
+// registers, in alphabetical order.
+func (a Analysis) Influences(register string) (influences []string) {
+	seen := map[string]bool{register: true}
+	todo := []string{register}
+
+	for len(todo) > 0 {
+		r := todo[0]
+		todo = todo[1:]
+
+		for _, d := range a.Dependencies {
+			if d.To == r && !seen[d.From] {
+				seen[d.From] = true
+				todo = append(todo, d.From)
+				influences = append(influences, d.From)
+			}
+		}
+	}
+
+	sort.Strings(influences)
+	return
+}
+
+// DOT returns the dependencies as a graph in the DOT language, dependencies through a condition are dashed.
+func (a Analysis) DOT() string {
+	var b strings.Builder
+
+	registers := make([]string, 0, len(a.Ranges))
+	for r := range a.Ranges {
+		registers = append(registers, r)
+	}
+	sort.Strings(registers)
+
+	fmt.Fprintln(&b, "digraph registers {")
+	for _, r := range registers {
+		fmt.Fprintf(&b, "\t%q [label=%q];\n", r, fmt.Sprintf("%v %v", r, a.Ranges[r]))
+	}
+	for _, d := range a.Dependencies {
+		if d.ThroughCondition {
+			fmt.Fprintf(&b, "\t%q -> %q [style=dashed];\n", d.From, d.To)
+		} else {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", d.From, d.To)
+		}
+	}
+	fmt.Fprintln(&b, "}")
+
+	return b.String()
+}
+
+func (a *Analysis) rangeOf(register string) Range {
+	r, ok := a.Ranges[register]
+	if !ok {
+		a.Ranges[register] = r
+	}
+	return r
+}
+
+func (a *Analysis) rangeOfValue(v value) Range {
+	if v.register != "" {
+		return a.rangeOf(v.register)
+	}
+	return Range{v.constant, v.constant}
+}
+
+func registersOf(c condition, f func(register string)) {
+	switch c := c.(type) {
+	case comparison:
+		for _, v := range []value{c.left, c.right} {
+			if v.register != "" {
+				f(v.register)
+			}
+		}
+	case conjunction:
+		registersOf(c.left, f)
+		registersOf(c.right, f)
+	case disjunction:
+		registersOf(c.left, f)
+		registersOf(c.right, f)
+	case negation:
+		registersOf(c.c, f)
+	}
+}
+
+type truth int
+
+const (
+	never truth = iota
+	maybe
+	always
+)
+
+func (a *Analysis) evaluate(c condition) truth {
+	switch c := c.(type) {
+	case comparison:
+		return compare(a.rangeOfValue(c.left), c.comparator, a.rangeOfValue(c.right))
+	case conjunction:
+		l, r := a.evaluate(c.left), a.evaluate(c.right)
+		if l < r {
+			return l
+		}
+		return r
+	case disjunction:
+		l, r := a.evaluate(c.left), a.evaluate(c.right)
+		if l > r {
+			return l
+		}
+		return r
+	case negation:
+		return always - a.evaluate(c.c)
+	default:
+		panic(fmt.Sprintf("unknown condition %T", c))
+	}
+}
+
+func compare(l Range, comparator string, r Range) truth {
+	switch comparator {
+	case "==":
+		if l.Min == l.Max && r.Min == r.Max && l.Min == r.Min {
+			return always
+		}
+		if l.Max < r.Min || r.Max < l.Min {
+			return never
+		}
+	case "!=":
+		return always - compare(l, "==", r)
+	case "<":
+		if l.Max < r.Min {
+			return always
+		}
+		if l.Min >= r.Max {
+			return never
+		}
+	case "<=":
+		if l.Max <= r.Min {
+			return always
+		}
+		if l.Min > r.Max {
+			return never
+		}
+	case ">":
+		return compare(r, "<", l)
+	case ">=":
+		return compare(r, "<=", l)
+	}
+	return maybe
+}
+
+// apply is operate for ranges: the range of a register in x after applying the operator with an argument in y
+func apply(operator string, x, y Range) Range {
+	switch operator {
+	case "inc":
+		return Range{addClamped(x.Min, y.Min), addClamped(x.Max, y.Max)}
+	case "dec":
+		return Range{addClamped(x.Min, negateClamped(y.Max)), addClamped(x.Max, negateClamped(y.Min))}
+	case "mul":
+		products := []int{mulClamped(x.Min, y.Min), mulClamped(x.Min, y.Max), mulClamped(x.Max, y.Min), mulClamped(x.Max, y.Max)}
+		r := Range{products[0], products[0]}
+		for _, p := range products[1:] {
+			r = join(r, Range{p, p})
+		}
+		return r
+	case "set":
+		return y
+	default:
+		panic(fmt.Sprintf("unknown operator %q", operator))
+	}
+}
+
+func join(x, y Range) Range {
+	if y.Min < x.Min {
+		x.Min = y.Min
+	}
+	if y.Max > x.Max {
+		x.Max = y.Max
+	}
+	return x
+}
+
+func addClamped(x, y int) int {
+	r := x + y
+	switch {
+	case x > 0 && y > 0 && r < 0:
+		return maxInt
+	case x < 0 && y < 0 && r >= 0:
+		return minInt
+	}
+	return r
+}
+
+func negateClamped(x int) int {
+	if x == minInt {
+		return maxInt
+	}
+	return -x
+}
+
+func mulClamped(x, y int) int {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	r := x * y
+	if r/y != x || (x == -1 && y == minInt) || (y == -1 && x == minInt) {
+		if (x < 0) == (y < 0) {
+			return maxInt
+		}
+		return minInt
+	}
+	return r
+}
@@ -0,0 +1,92 @@
+package instruction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	input := `a inc 5 if x == 0
+b inc a if b < 10
+c dec 1 if a > 5
+d set 2 if y > 0 or x == 0
+d mul -3 if c != 0 and d == 2
+e inc d if not a == 5`
+
+	irs, err := ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := Analyze(irs)
+
+	if expected := []int{2, 4, 5}; !reflect.DeepEqual(a.NeverFires, expected) {
+		t.Errorf("expected instructions %v to never fire, but got %v", expected, a.NeverFires)
+	}
+
+	expectedRanges := map[string]Range{
+		"a": {5, 5},
+		"b": {5, 5},
+		"c": {0, 0},
+		"d": {2, 2},
+		"e": {0, 0},
+		"x": {0, 0},
+		"y": {0, 0},
+	}
+	if !reflect.DeepEqual(a.Ranges, expectedRanges) {
+		t.Errorf("expected ranges %v, but got %v", expectedRanges, a.Ranges)
+	}
+
+	expectedDependencies := []Dependency{
+		{"x", "a", true},
+		{"a", "b", false},
+		{"x", "d", true},
+		{"y", "d", true},
+	}
+	if !reflect.DeepEqual(a.Dependencies, expectedDependencies) {
+		t.Errorf("expected dependencies %v, but got %v", expectedDependencies, a.Dependencies)
+	}
+
+	if got, expected := a.Influences("b"), []string{"a", "x"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Influences(\"b\") = %v, but expected %v", got, expected)
+	}
+}
+
+func TestAnalyze_Ranges(t *testing.T) {
+	input := `a inc 3 if b == 0
+a inc 2 if c > 0
+b set a if a >= 3
+b mul -2 if c == 0
+c dec b if c <= 0`
+
+	irs, err := ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := Analyze(irs)
+
+	expected := map[string]Range{"a": {3, 3}, "b": {-6, -6}, "c": {6, 6}}
+	if !reflect.DeepEqual(a.Ranges, expected) {
+		t.Errorf("expected ranges %v, but got %v", expected, a.Ranges)
+	}
+}
+
+func TestAnalysis_DOT(t *testing.T) {
+	irs, err := ParseListOfJumpInstructions("a inc b if c >= 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `digraph registers {
+	"a" [label="a [0, 0]"];
+	"b" [label="b [0, 0]"];
+	"c" [label="c [0, 0]"];
+	"b" -> "a";
+	"c" -> "a" [style=dashed];
+}
+`
+	if got := Analyze(irs).DOT(); got != expected {
+		t.Errorf("DOT() =\n%v\nbut expected\n%v", got, expected)
+	}
+}
@@ -0,0 +1,205 @@
+package instruction
+
+import "fmt"
+
+type condition interface {
+	fmt.Stringer
+	holds(bank RegisterBank) bool
+}
+
+type comparison struct {
+	left       value
+	comparator string
+	right      value
+}
+
+func (c comparison) holds(bank RegisterBank) bool {
+	left, right := c.left.of(bank), c.right.of(bank)
+
+	switch c.comparator {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case ">":
+		return left > right
+	case "<":
+		return left < right
+	case ">=":
+		return left >= right
+	case "<=":
+		return left <= right
+	default:
+		panic(fmt.Sprintf("unknown comparator %q", c.comparator))
+	}
+}
+
+func (c comparison) String() string {
+	return fmt.Sprintf("%v %v %v", c.left, c.comparator, c.right)
+}
+
+type conjunction struct {
+	left, right condition
+}
+
+func (c conjunction) holds(bank RegisterBank) bool {
+	return c.left.holds(bank) && c.right.holds(bank)
+}
+
+func (c conjunction) String() string {
+	return fmt.Sprintf("(%v and %v)", c.left, c.right)
+}
+
+type disjunction struct {
+	left, right condition
+}
+
+func (c disjunction) holds(bank RegisterBank) bool {
+	return c.left.holds(bank) || c.right.holds(bank)
+}
+
+func (c disjunction) String() string {
+	return fmt.Sprintf("(%v or %v)", c.left, c.right)
+}
+
+type negation struct {
+	c condition
+}
+
+func (c negation) holds(bank RegisterBank) bool {
+	return !c.c.holds(bank)
+}
+
+func (c negation) String() string {
+	return fmt.Sprintf("not %v", c.c)
+}
+
+var comparators = map[string]bool{"==": true, "!=": true, ">": true, "<": true, ">=": true, "<=": true}
+
+// parseCondition parses a condition from its tokens:
+//
+//	condition  = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" condition ")" | comparison
+//	comparison = value comparator value
+//
+// where a value is an integer or a register.
+func parseCondition(tokens []string) (condition, error) {
+	p := conditionParser{tokens: tokens}
+
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, UnexpectedTokenError{p.tokens[p.pos]}
+	}
+	return cond, nil
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token, or "" at the end of the line
+func (p *conditionParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	p.pos += 1
+	return p.tokens[p.pos-1]
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "or" {
+		p.next()
+
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = disjunction{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) and() (condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "and" {
+		p.next()
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = conjunction{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) unary() (condition, error) {
+	switch p.peek() {
+	case "not":
+		p.next()
+
+		cond, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return negation{cond}, nil
+
+	case "(":
+		p.next()
+
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.next(); token != ")" {
+			return nil, UnexpectedTokenError{token}
+		}
+		return cond, nil
+
+	default:
+		return p.comparison()
+	}
+}
+
+func (p *conditionParser) comparison() (condition, error) {
+	if p.pos+3 > len(p.tokens) {
+		p.pos = len(p.tokens)
+		return nil, UnexpectedTokenError{""}
+	}
+
+	left, err := parseValue(p.next())
+	if err != nil {
+		return nil, err
+	}
+	comparator := p.next()
+	right, err := parseValue(p.next())
+	if err != nil {
+		return nil, err
+	}
+
+	if !comparators[comparator] {
+		return nil, UnknownComparatorError{comparator}
+	}
+	return comparison{left, comparator, right}, nil
+}
@@ -0,0 +1,48 @@
+package instruction
+
+import "fmt"
+
+// MalformedInstructionError is returned for a line that is not of the form "<reg> <op> <val> if <condition>".
+type MalformedInstructionError struct {
+	Text string
+}
+
+func (e MalformedInstructionError) Error() string {
+	return fmt.Sprintf("malformed instruction %q", e.Text)
+}
+
+type UnknownOperatorError struct {
+	Operator string
+}
+
+func (e UnknownOperatorError) Error() string {
+	return fmt.Sprintf("unknown operator %q", e.Operator)
+}
+
+type UnknownComparatorError struct {
+	Comparator string
+}
+
+func (e UnknownComparatorError) Error() string {
+	return fmt.Sprintf("unknown comparator %q", e.Comparator)
+}
+
+type BadIntegerError struct {
+	Text string
+}
+
+func (e BadIntegerError) Error() string {
+	return fmt.Sprintf("bad integer %q", e.Text)
+}
+
+// UnexpectedTokenError is returned for a condition that is not well-formed, an empty Token is the end of the line.
+type UnexpectedTokenError struct {
+	Token string
+}
+
+func (e UnexpectedTokenError) Error() string {
+	if e.Token == "" {
+		return "unexpected end of line"
+	}
+	return fmt.Sprintf("unexpected %q", e.Token)
+}
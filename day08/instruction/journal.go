@@ -0,0 +1,114 @@
+package instruction
+
+import "sort"
+
+// Write is a change of a register by the instruction evaluated at Step, counting from 0.
+type Write struct {
+	Step     int
+	Register string
+	Old, New int
+}
+
+// Journal evaluates instructions on a RegisterBank and records every write, so the history of the registers can be
+// queried without evaluating the instructions again.
+type Journal struct {
+	Bank   RegisterBank
+	Writes []Write
+
+	steps   int
+	initial map[string]int
+	// indices in Writes, per register in order of step
+	byRegister map[string][]int
+	// index in Writes of the largest value, per register and overall
+	maxByRegister map[string]int
+	max           int
+}
+
+// NewJournal records the writes on bank, the values it holds now are the values at step 0.
+func NewJournal(bank RegisterBank) *Journal {
+	initial := make(map[string]int, len(bank))
+	for r, v := range bank {
+		initial[r] = v
+	}
+
+	return &Journal{
+		Bank:          bank,
+		initial:       initial,
+		byRegister:    make(map[string][]int),
+		maxByRegister: make(map[string]int),
+		max:           -1,
+	}
+}
+
+func (j *Journal) Evaluate(ir JumpInstruction) {
+	old := j.Bank[ir.register]
+
+	if ir.evaluateOn(j.Bank) {
+		j.record(Write{j.steps, ir.register, old, j.Bank[ir.register]})
+	}
+	j.steps += 1
+}
+
+func (j *Journal) EvaluateAll(irs []JumpInstruction) {
+	for _, ir := range irs {
+		j.Evaluate(ir)
+	}
+}
+
+func (j *Journal) record(w Write) {
+	i := len(j.Writes)
+	j.Writes = append(j.Writes, w)
+	j.byRegister[w.Register] = append(j.byRegister[w.Register], i)
+
+	if m, ok := j.maxByRegister[w.Register]; !ok || w.New > j.Writes[m].New {
+		j.maxByRegister[w.Register] = i
+	}
+	if j.max < 0 || w.New > j.Writes[j.max].New {
+		j.max = i
+	}
+}
+
+// Steps returns the number of instructions evaluated.
+func (j *Journal) Steps() int {
+	return j.steps
+}
+
+// ValueAt returns the value of register after the first step instructions were evaluated.
+func (j *Journal) ValueAt(register string, step int) int {
+	if w, ok := j.SetBy(register, step); ok {
+		return w.New
+	}
+	return j.initial[register]
+}
+
+// SetBy returns the write that set the value register holds after the first step instructions were evaluated, if
+// it has been written at all by then.
+func (j *Journal) SetBy(register string, step int) (Write, bool) {
+	writes := j.byRegister[register]
+
+	// the number of writes before step
+	n := sort.Search(len(writes), func(i int) bool {
+		return j.Writes[writes[i]].Step >= step
+	})
+	if n == 0 {
+		return Write{}, false
+	}
+	return j.Writes[writes[n-1]], true
+}
+
+// Max returns the write of the largest value to any register, the first one if there are several.
+func (j *Journal) Max() (Write, bool) {
+	if j.max < 0 {
+		return Write{}, false
+	}
+	return j.Writes[j.max], true
+}
+
+// MaxOf returns the write of the largest value to register, the first one if there are several.
+func (j *Journal) MaxOf(register string) (Write, bool) {
+	i, ok := j.maxByRegister[register]
+	if !ok {
+		return Write{}, false
+	}
+	return j.Writes[i], true
+}
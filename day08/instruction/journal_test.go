@@ -0,0 +1,57 @@
+package instruction
+
+import (
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	input := `b inc 5 if a > 1
+a inc 1 if b < 5
+c dec -10 if a >= 1
+c inc -20 if c == 10
+a inc 3 if c < 0`
+
+	irs, err := ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJournal(NewRegisterBank())
+	j.EvaluateAll(irs)
+
+	if j.Steps() != 5 || len(j.Writes) != 4 {
+		t.Errorf("expected 5 steps and 4 writes, but got %v and %v", j.Steps(), len(j.Writes))
+	}
+
+	values := []struct {
+		register string
+		step     int
+		value    int
+	}{
+		{"a", 0, 0},
+		{"a", 2, 1},
+		{"a", 5, 4},
+		{"b", 5, 0},
+		{"c", 3, 10},
+		{"c", 4, -10},
+	}
+	for _, v := range values {
+		if got := j.ValueAt(v.register, v.step); got != v.value {
+			t.Errorf("ValueAt(%q, %v) = %v, but expected %v", v.register, v.step, got, v.value)
+		}
+	}
+
+	if w, ok := j.SetBy("c", 5); !ok || w != (Write{3, "c", 10, -10}) {
+		t.Errorf("SetBy(\"c\", 5) = %v, %v", w, ok)
+	}
+	if _, ok := j.SetBy("b", 5); ok {
+		t.Errorf("expected register \"b\" to never be written")
+	}
+
+	if w, ok := j.Max(); !ok || w != (Write{2, "c", 0, 10}) {
+		t.Errorf("Max() = %v, %v", w, ok)
+	}
+	if w, ok := j.MaxOf("a"); !ok || w != (Write{4, "a", 1, 4}) {
+		t.Errorf("MaxOf(\"a\") = %v, %v", w, ok)
+	}
+}
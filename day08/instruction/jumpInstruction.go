@@ -2,105 +2,144 @@ package instruction
 
 import (
 	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/util"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
-type operationFunc func(bank RegisterBank)
-type conditionFunc func(bank RegisterBank) bool
-
-type JumpInstruction struct {
-	operation operationFunc
-	condition conditionFunc
+// value is a constant or, if register is set, the value of a register
+type value struct {
+	register string
+	constant int
 }
 
-func (ir *JumpInstruction) evaluateOn(bank RegisterBank) {
-	if ir.condition(bank) {
-		ir.operation(bank)
+func (v value) of(bank RegisterBank) int {
+	if v.register != "" {
+		return bank[v.register]
 	}
+	return v.constant
 }
 
-func ParseListOfJumpInstructions(input string) []JumpInstruction {
-	lines := strings.Split(input, "\n")
-
-	irs := make([]JumpInstruction, len(lines))
-
-	for i, line := range lines {
-		irs[i] = parseJumpInstruction(line)
+func (v value) String() string {
+	if v.register != "" {
+		return v.register
 	}
-
-	return irs
+	return strconv.Itoa(v.constant)
 }
 
-func parseJumpInstruction(input string) JumpInstruction {
-	var opRegister string
-	var opOperator string
-	var opArg int
+type JumpInstruction struct {
+	// the register written by the operator
+	register  string
+	operator  string
+	argument  value
+	condition condition
+}
 
-	var condRegister string
-	var condOperator string
-	var condArg int
+func (ir JumpInstruction) String() string {
+	return fmt.Sprintf("%v %v %v if %v", ir.register, ir.operator, ir.argument, ir.condition)
+}
 
-	n, err := fmt.Sscanf(input, "%s %s %d if %s %s %d", &opRegister, &opOperator, &opArg, &condRegister, &condOperator, &condArg)
-	if err != nil {
-		panic(fmt.Sprintf("could not correctly parse JumpInstruction: n = %v, err = %v", n, err))
+// evaluateOn returns whether the condition held and the operation was executed
+func (ir *JumpInstruction) evaluateOn(bank RegisterBank) bool {
+	if ir.condition.holds(bank) {
+		bank[ir.register] = operate(ir.operator, bank[ir.register], ir.argument.of(bank))
+		return true
 	}
+	return false
+}
 
-	op := makeOperation(opRegister, opOperator, opArg)
-	cond := makeCondition(condRegister, condOperator, condArg)
+var operators = map[string]bool{"inc": true, "dec": true, "mul": true, "set": true}
 
-	return JumpInstruction{op, cond}
+// operate returns the new value of a register holding x after applying the operator with argument y
+func operate(operator string, x, y int) int {
+	switch operator {
+	case "inc":
+		return x + y
+	case "dec":
+		return x - y
+	case "mul":
+		return x * y
+	case "set":
+		return y
+	default:
+		panic(fmt.Sprintf("unknown operator %q", operator))
+	}
 }
 
-func makeOperation(opRegister string, opOperator string, opArg int) operationFunc {
-	switch opOperator {
-	case "inc":
-		return func(bank RegisterBank) {
-			bank[opRegister] += opArg
+// ParseListOfJumpInstructions parses an instruction per line, blank lines are skipped. If any line can not be
+// parsed, the error is a util.LineErrors listing all of them.
+func ParseListOfJumpInstructions(input string) ([]JumpInstruction, error) {
+	var irs []JumpInstruction
+	var parseErrors util.LineErrors
+
+	for i, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
 		}
 
-	case "dec":
-		return func(bank RegisterBank) {
-			bank[opRegister] -= opArg
+		ir, err := parseJumpInstruction(line)
+		if err != nil {
+			parseErrors = append(parseErrors, util.LineError{Line: i + 1, Err: err})
+			continue
 		}
+		irs = append(irs, ir)
+	}
 
-	default:
-		panic(fmt.Sprintf("could not create operationFunc, unknown opOperator = %v", opOperator))
+	if len(parseErrors) > 0 {
+		return irs, parseErrors
 	}
+	return irs, nil
 }
 
-func makeCondition(condRegister string, condOperator string, condArg int) conditionFunc {
-	switch condOperator {
-	case "==":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] == condArg
-		}
+// parseJumpInstruction parses "<reg> <op> <val> if <condition>", see parseCondition for the conditions.
+func parseJumpInstruction(input string) (ir JumpInstruction, err error) {
+	tokens := tokenize(input)
+	if len(tokens) < 5 || tokens[3] != "if" || !isRegister(tokens[0]) {
+		return ir, MalformedInstructionError{input}
+	}
 
-	case "!=":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] != condArg
-		}
+	opArg, err := parseValue(tokens[2])
+	if err != nil {
+		return
+	}
+	if !operators[tokens[1]] {
+		return ir, UnknownOperatorError{tokens[1]}
+	}
+	cond, err := parseCondition(tokens[4:])
+	if err != nil {
+		return
+	}
 
-	case ">":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] > condArg
-		}
+	return JumpInstruction{tokens[0], tokens[1], opArg, cond}, nil
+}
 
-	case "<":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] < condArg
-		}
+// tokenize splits on whitespace, parentheses are tokens of their own
+func tokenize(input string) (tokens []string) {
+	input = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(input)
+	return strings.Fields(input)
+}
 
-	case ">=":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] >= condArg
+func isRegister(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
 		}
+	}
+	return s != "" && !keywords[s]
+}
 
-	case "<=":
-		return func(bank RegisterBank) bool {
-			return bank[condRegister] <= condArg
-		}
+var keywords = map[string]bool{"if": true, "and": true, "or": true, "not": true}
 
-	default:
-		panic(fmt.Sprintf("could not create conditionFunc, unknown condOperator = %v", condOperator))
+// parseValue parses an integer or the name of a register
+func parseValue(s string) (value, error) {
+	if isRegister(s) {
+		return value{register: s}, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return value{}, BadIntegerError{s}
 	}
+	return value{constant: n}, nil
 }
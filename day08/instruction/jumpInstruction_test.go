@@ -0,0 +1,87 @@
+package instruction
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/util"
+	"reflect"
+	"testing"
+)
+
+func TestParseListOfJumpInstructions_BlankLines(t *testing.T) {
+	input := `
+b inc 5 if a > 1
+
+a inc 1 if b < 5
+`
+	irs, err := ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(irs) != 2 {
+		t.Errorf("expected 2 instructions, but got %v", len(irs))
+	}
+}
+
+func TestParseListOfJumpInstructions_Errors(t *testing.T) {
+	input := `b inc 5 if a > 1
+a mod 1 if b < 5
+c dec 1x if a >= 1
+c inc -20 if c =< 10
+c inc -20 when c == 10
+a inc 1 if b < 5`
+
+	irs, err := ParseListOfJumpInstructions(input)
+
+	if len(irs) != 2 {
+		t.Errorf("expected 2 valid instructions, but got %v", len(irs))
+	}
+
+	expected := util.LineErrors{
+		{Line: 2, Err: UnknownOperatorError{"mod"}},
+		{Line: 3, Err: BadIntegerError{"1x"}},
+		{Line: 4, Err: UnknownComparatorError{"=<"}},
+		{Line: 5, Err: MalformedInstructionError{"c inc -20 when c == 10"}},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected errors %v, but got %v", expected, err)
+	}
+}
+
+func TestParseListOfJumpInstructions_Conditions(t *testing.T) {
+	input := `a set 3 if a == 0
+b inc a if not (a < 3 or a > 3)
+c mul 2 if c == 0 and a == b or c < 0
+c set 5 if c == 0 and (a == b or c < 0)
+d dec b if b >= 3 and not a != b`
+
+	irs, err := ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bank := NewRegisterBank()
+	bank.EvaluateAll(irs)
+
+	expected := RegisterBank{"a": 3, "b": 3, "c": 5, "d": -3}
+	if !reflect.DeepEqual(bank, expected) {
+		t.Errorf("expected registers %v, but got %v", expected, bank)
+	}
+}
+
+func TestParseListOfJumpInstructions_ConditionErrors(t *testing.T) {
+	input := `a inc 1 if (a == 0
+a inc 1 if a == 0)
+a inc 1 if a == 0 and
+a inc 1 if not`
+
+	_, err := ParseListOfJumpInstructions(input)
+
+	expected := util.LineErrors{
+		{Line: 1, Err: UnexpectedTokenError{""}},
+		{Line: 2, Err: UnexpectedTokenError{")"}},
+		{Line: 3, Err: UnexpectedTokenError{""}},
+		{Line: 4, Err: UnexpectedTokenError{""}},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected errors %v, but got %v", expected, err)
+	}
+}
@@ -8,7 +8,10 @@ import (
 func main() {
 	fmt.Println("Advent of Code 2017 - day 08")
 
-	instructions := instruction.ParseListOfJumpInstructions(input)
+	instructions, err := instruction.ParseListOfJumpInstructions(input)
+	if err != nil {
+		panic(err)
+	}
 
 	bank := instruction.NewRegisterBank()
 	bank.EvaluateAll(instructions)
@@ -35,14 +38,13 @@ func registerWithLargestValue(bank instruction.RegisterBank) (name string, value
 }
 
 func registerWithLargestValueDuringEvaluation(instructions []instruction.JumpInstruction, bank instruction.RegisterBank) (name string, value int) {
-	for _, ir := range instructions {
-		bank.Evaluate(ir)
+	name, value = registerWithLargestValue(bank)
 
-		largestRegister, largestValue := registerWithLargestValue(bank)
-		if largestValue > value {
-			name = largestRegister
-			value = largestValue
-		}
+	journal := instruction.NewJournal(bank)
+	journal.EvaluateAll(instructions)
+
+	if w, ok := journal.Max(); ok && w.New > value {
+		name, value = w.Register, w.New
 	}
 	return
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestEvaluateOn(t *testing.T) {
-	instructions := loadExampleInput()
+	instructions := loadExampleInput(t)
 
 	bank := instruction.NewRegisterBank()
 	bank.EvaluateAll(instructions)
@@ -33,7 +33,7 @@ func TestEvaluateOn(t *testing.T) {
 }
 
 func Test_registerWithLargestValue(t *testing.T) {
-	instructions := loadExampleInput()
+	instructions := loadExampleInput(t)
 
 	bank := instruction.NewRegisterBank()
 	bank.EvaluateAll(instructions)
@@ -46,7 +46,7 @@ func Test_registerWithLargestValue(t *testing.T) {
 }
 
 func Test_registerWithLargestValueDuringEvaluation(t *testing.T) {
-	instructions := loadExampleInput()
+	instructions := loadExampleInput(t)
 
 	bank := instruction.NewRegisterBank()
 
@@ -57,12 +57,15 @@ func Test_registerWithLargestValueDuringEvaluation(t *testing.T) {
 	}
 }
 
-func loadExampleInput() []instruction.JumpInstruction {
+func loadExampleInput(t *testing.T) []instruction.JumpInstruction {
 	input := `b inc 5 if a > 1
 a inc 1 if b < 5
 c dec -10 if a >= 1
 c inc -20 if c == 10`
 
-	instructions := instruction.ParseListOfJumpInstructions(input)
+	instructions, err := instruction.ParseListOfJumpInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
 	return instructions
 }
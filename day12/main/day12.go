@@ -1,18 +1,37 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/koenaad/Advent-of-Code-2017/day12/program"
+	"os"
 )
 
+var export = flag.String("export", "", "write the pipes to stderr, either \"dot\" or \"graphml\"")
+
 func main() {
+	flag.Parse()
+
 	fmt.Println("Advent of Code 2017 - day 12")
 
-	reports := program.ParseListOfReachabilityReports(input)
+	reports, err := program.ParseListOfReachabilityReports(input)
+	if err != nil {
+		panic(err)
+	}
 	groups := program.DivideIntoReachabilityGroups(reports)
 
 	fmt.Printf("Puzzle 1: amount of programs in group with Program ID 0: %v\n", sizeOfGroupWithProgram0(groups))
 	fmt.Printf("Puzzle 2: amount of programs: %v\n", len(groups))
+
+	switch *export {
+	case "dot":
+		err = program.WriteDOT(os.Stderr, reports, groups)
+	case "graphml":
+		err = program.WriteGraphML(os.Stderr, reports, groups)
+	}
+	if err != nil {
+		panic(err)
+	}
 }
 
 func sizeOfGroupWithProgram0(groups []program.ProgramSet) int {
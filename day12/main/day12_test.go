@@ -14,7 +14,10 @@ func Test_exampleInput(t *testing.T) {
 5 <-> 6
 6 <-> 4, 5`
 
-	reports := program.ParseListOfReachabilityReports(input)
+	reports, err := program.ParseListOfReachabilityReports(input)
+	if err != nil {
+		t.Fatal(err)
+	}
 	groups := program.DivideIntoReachabilityGroups(reports)
 
 	gotSizeOfGroupWithProgram0 := sizeOfGroupWithProgram0(groups)
@@ -0,0 +1,110 @@
+package program
+
+// DisjointSet keeps track of the groups of programs that can communicate with each other, directly or through other
+// programs. It uses path compression and union by rank, so adding reports and finding groups takes nearly constant
+// time.
+type DisjointSet struct {
+	parent map[ProgramId]ProgramId
+	rank   map[ProgramId]int
+	// the size of every group, by the root of the group
+	size map[ProgramId]int
+	// all programs in order of appearance
+	programs []ProgramId
+}
+
+func NewDisjointSet() *DisjointSet {
+	return &DisjointSet{
+		parent: make(map[ProgramId]ProgramId),
+		rank:   make(map[ProgramId]int),
+		size:   make(map[ProgramId]int),
+	}
+}
+
+// Add puts the program of the report in the same group as all programs it can communicate with.
+func (s *DisjointSet) Add(report ReachabilityReport) {
+	s.add(report.Program)
+	for _, p := range report.CanCommunicateWith {
+		s.add(p)
+		s.union(report.Program, p)
+	}
+}
+
+func (s *DisjointSet) add(p ProgramId) {
+	if _, ok := s.parent[p]; ok {
+		return
+	}
+	s.parent[p] = p
+	s.size[p] = 1
+	s.programs = append(s.programs, p)
+}
+
+// Find returns the program representing the group p is in, a program that has not been added is a group on its own.
+func (s *DisjointSet) Find(p ProgramId) ProgramId {
+	root := p
+	for {
+		parent, ok := s.parent[root]
+		if !ok || parent == root {
+			break
+		}
+		root = parent
+	}
+
+	// path compression
+	for p != root {
+		next := s.parent[p]
+		s.parent[p] = root
+		p = next
+	}
+	return root
+}
+
+func (s *DisjointSet) union(a, b ProgramId) {
+	a, b = s.Find(a), s.Find(b)
+	if a == b {
+		return
+	}
+
+	if s.rank[a] < s.rank[b] {
+		a, b = b, a
+	}
+	if s.rank[a] == s.rank[b] {
+		s.rank[a] += 1
+	}
+
+	s.parent[b] = a
+	s.size[a] += s.size[b]
+	delete(s.size, b)
+	delete(s.rank, b)
+}
+
+func (s *DisjointSet) SameGroup(a, b ProgramId) bool {
+	return s.Find(a) == s.Find(b)
+}
+
+// GroupSizes returns the size of every group, by the program representing it.
+func (s *DisjointSet) GroupSizes() map[ProgramId]int {
+	sizes := make(map[ProgramId]int, len(s.size))
+	for root, size := range s.size {
+		sizes[root] = size
+	}
+	return sizes
+}
+
+// Groups returns every group as a ProgramSet, in order of the first appearance of any of their programs.
+func (s *DisjointSet) Groups() []ProgramSet {
+	var groups []ProgramSet
+	index := make(map[ProgramId]int)
+
+	for _, p := range s.programs {
+		root := s.Find(p)
+
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, newProgramSet())
+		}
+		groups[i].add(p)
+	}
+	return groups
+}
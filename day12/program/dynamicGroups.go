@@ -0,0 +1,201 @@
+package program
+
+import "math/rand"
+
+// DynamicGroups keeps track of the groups of programs while pipes are connected and disconnected, using the
+// algorithm of Holm, de Lichtenberg and Thorup: updates take O(log² n) amortized time, queries O(log n).
+//
+// Every pipe has a level, which only increases. The forest at level i is a spanning forest of the pipes with a
+// level of at least i, so the forest at level 0 spans all groups. When a pipe of a spanning tree is disconnected,
+// the smallest half of the tree is searched for a replacement, starting at the level of the pipe. Pipes that turn
+// out not to be a replacement are moved up a level, which can happen at most log n times per pipe.
+type DynamicGroups struct {
+	ids    map[ProgramId]int
+	levels []*connectivityLevel
+	pipes  map[edge]pipeState
+	rand   *rand.Rand
+}
+
+type pipeState struct {
+	level int
+	tree  bool
+}
+
+type connectivityLevel struct {
+	forest *eulerTourForest
+	// the tree and non-tree pipes of exactly this level, by both programs they connect
+	tree, nonTree map[int]map[int]bool
+}
+
+func NewDynamicGroups() *DynamicGroups {
+	return &DynamicGroups{
+		ids:   make(map[ProgramId]int),
+		pipes: make(map[edge]pipeState),
+		rand:  rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add connects the program of the report to all programs it can communicate with.
+func (g *DynamicGroups) Add(report ReachabilityReport) {
+	for _, p := range report.CanCommunicateWith {
+		g.Connect(report.Program, p)
+	}
+}
+
+// Connect adds a pipe between a and b, connecting programs that are already connected or a program to itself has
+// no effect.
+func (g *DynamicGroups) Connect(a, b ProgramId) {
+	u, v := g.id(a), g.id(b)
+	e := newEdge(u, v)
+	if u == v {
+		return
+	}
+	if _, ok := g.pipes[e]; ok {
+		return
+	}
+
+	l := g.level(0)
+	if l.forest.connected(u, v) {
+		g.pipes[e] = pipeState{0, false}
+		g.addTo(0, l.nonTree, u, v, hasNonTreeEdges)
+		return
+	}
+
+	g.pipes[e] = pipeState{0, true}
+	g.addTo(0, l.tree, u, v, hasTreeEdges)
+	l.forest.link(u, v)
+}
+
+// Disconnect removes the pipe between a and b, if there is one.
+func (g *DynamicGroups) Disconnect(a, b ProgramId) {
+	u, v := g.id(a), g.id(b)
+	e := newEdge(u, v)
+
+	state, ok := g.pipes[e]
+	if !ok {
+		return
+	}
+	delete(g.pipes, e)
+
+	l := g.levels[state.level]
+	if !state.tree {
+		g.removeFrom(state.level, l.nonTree, u, v, hasNonTreeEdges)
+		return
+	}
+
+	g.removeFrom(state.level, l.tree, u, v, hasTreeEdges)
+	for i := 0; i <= state.level; i++ {
+		g.levels[i].forest.cut(u, v)
+	}
+
+	for i := state.level; i >= 0; i-- {
+		if g.replace(u, v, i) {
+			return
+		}
+	}
+}
+
+func (g *DynamicGroups) Connected(a, b ProgramId) bool {
+	if a == b {
+		return true
+	}
+	u, okA := g.ids[a]
+	v, okB := g.ids[b]
+	if !okA || !okB {
+		return false
+	}
+	return g.level(0).forest.connected(u, v)
+}
+
+// GroupSize returns the amount of programs connected to p, including p.
+func (g *DynamicGroups) GroupSize(p ProgramId) int {
+	u, ok := g.ids[p]
+	if !ok {
+		return 1
+	}
+	return g.level(0).forest.treeSize(u)
+}
+
+// replace searches a pipe of level i to reconnect the trees of u and v in the forest of level i
+func (g *DynamicGroups) replace(u, v, i int) bool {
+	l := g.levels[i]
+
+	// search the smallest tree
+	if l.forest.treeSize(u) > l.forest.treeSize(v) {
+		u, v = v, u
+	}
+
+	// move its tree pipes up a level, so the forest of level i + 1 still spans the pipes of at least that level
+	up := g.level(i + 1)
+	for x := l.forest.findFlagged(u, hasTreeEdges); x >= 0; x = l.forest.findFlagged(u, hasTreeEdges) {
+		for y := range l.tree[x] {
+			g.removeFrom(i, l.tree, x, y, hasTreeEdges)
+			g.addTo(i+1, up.tree, x, y, hasTreeEdges)
+			g.pipes[newEdge(x, y)] = pipeState{i + 1, true}
+			up.forest.link(x, y)
+		}
+	}
+
+	for x := l.forest.findFlagged(u, hasNonTreeEdges); x >= 0; x = l.forest.findFlagged(u, hasNonTreeEdges) {
+		for y := range l.nonTree[x] {
+			g.removeFrom(i, l.nonTree, x, y, hasNonTreeEdges)
+
+			if !l.forest.connected(x, y) {
+				// y is in the tree of v, so this pipe reconnects them
+				g.pipes[newEdge(x, y)] = pipeState{i, true}
+				g.addTo(i, l.tree, x, y, hasTreeEdges)
+				for j := 0; j <= i; j++ {
+					g.levels[j].forest.link(x, y)
+				}
+				return true
+			}
+
+			// both ends are in the tree of u
+			g.pipes[newEdge(x, y)] = pipeState{i + 1, false}
+			g.addTo(i+1, up.nonTree, x, y, hasNonTreeEdges)
+		}
+	}
+	return false
+}
+
+func (g *DynamicGroups) id(p ProgramId) int {
+	i, ok := g.ids[p]
+	if !ok {
+		i = len(g.ids)
+		g.ids[p] = i
+	}
+	return i
+}
+
+func (g *DynamicGroups) level(i int) *connectivityLevel {
+	for len(g.levels) <= i {
+		g.levels = append(g.levels, &connectivityLevel{
+			forest:  newEulerTourForest(g.rand),
+			tree:    make(map[int]map[int]bool),
+			nonTree: make(map[int]map[int]bool),
+		})
+	}
+	return g.levels[i]
+}
+
+// addTo adds the pipe between u and v to pipes, a set of pipes of level i marked with flag
+func (g *DynamicGroups) addTo(i int, pipes map[int]map[int]bool, u, v int, flag uint8) {
+	for _, x := range [][2]int{{u, v}, {v, u}} {
+		if pipes[x[0]] == nil {
+			pipes[x[0]] = make(map[int]bool)
+		}
+		pipes[x[0]][x[1]] = true
+		g.levels[i].forest.setFlag(x[0], flag, true)
+	}
+}
+
+// removeFrom removes the pipe between u and v from pipes, a set of pipes of level i marked with flag
+func (g *DynamicGroups) removeFrom(i int, pipes map[int]map[int]bool, u, v int, flag uint8) {
+	for _, x := range [][2]int{{u, v}, {v, u}} {
+		delete(pipes[x[0]], x[1])
+		if len(pipes[x[0]]) == 0 {
+			delete(pipes, x[0])
+			g.levels[i].forest.setFlag(x[0], flag, false)
+		}
+	}
+}
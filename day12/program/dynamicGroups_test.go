@@ -0,0 +1,95 @@
+package program
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDynamicGroups(t *testing.T) {
+	g := NewDynamicGroups()
+	for _, report := range parse(t, graphInput) {
+		g.Add(report)
+	}
+
+	if !g.Connected(0, 6) || g.GroupSize(0) != 7 {
+		t.Errorf("expected 0 and 6 to be connected in a group of 7")
+	}
+
+	g.Disconnect(2, 3)
+	if g.Connected(0, 6) || g.GroupSize(0) != 3 || g.GroupSize(6) != 4 {
+		t.Errorf("expected 0 and 6 to be disconnected, in a group of 3 and 4")
+	}
+
+	g.Disconnect(3, 4)
+	if !g.Connected(3, 4) {
+		t.Errorf("expected 3 and 4 to be connected through 5")
+	}
+
+	g.Connect(1, 6)
+	if !g.Connected(0, 4) || g.Connected(0, 7) {
+		t.Errorf("expected 0 to be connected to 4 and not to 7")
+	}
+}
+
+// TestDynamicGroups_Random compares random connects and disconnects with searching the pipes from scratch.
+func TestDynamicGroups_Random(t *testing.T) {
+	const n = 40
+	r := rand.New(rand.NewSource(42))
+
+	g := NewDynamicGroups()
+	pipes := make(map[Pipe]bool)
+
+	for step := 0; step < 2000; step++ {
+		a, b := ProgramId(r.Intn(n)), ProgramId(r.Intn(n))
+
+		// connect more often at first, disconnect more often later on
+		if r.Intn(2000) > step {
+			g.Connect(a, b)
+			if a != b {
+				pipes[newPipe(a, b)] = true
+			}
+		} else {
+			g.Disconnect(a, b)
+			delete(pipes, newPipe(a, b))
+		}
+
+		var reports []ReachabilityReport
+		for pipe := range pipes {
+			reports = append(reports, ReachabilityReport{pipe.A, []ProgramId{pipe.B}})
+		}
+		reference := NewGraph(reports)
+
+		for p := ProgramId(0); p < n; p++ {
+			distances := reference.Distances(p)
+
+			if g.GroupSize(p) != len(distances) {
+				t.Fatalf("step %v: GroupSize(%v) = %v, but expected %v", step, p, g.GroupSize(p), len(distances))
+			}
+			for q := ProgramId(0); q < n; q++ {
+				_, expected := distances[q]
+				if got := g.Connected(p, q); got != expected {
+					t.Fatalf("step %v: Connected(%v, %v) = %v, but expected %v", step, p, q, got, expected)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDynamicGroups_Random100k(b *testing.B) {
+	reports := syntheticReports(100000, 1)
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		g := NewDynamicGroups()
+		for _, report := range reports {
+			g.Add(report)
+		}
+		for j := 0; j < 10000; j++ {
+			report := reports[r.Intn(len(reports))]
+			if len(report.CanCommunicateWith) > 0 {
+				g.Disconnect(report.Program, report.CanCommunicateWith[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package program
+
+import "math/rand"
+
+// flags marked on the vertex nodes of an Euler tour tree
+const (
+	// the vertex has tree edges of the level of the forest
+	hasTreeEdges uint8 = 1 << iota
+	// the vertex has non-tree edges of the level of the forest
+	hasNonTreeEdges
+)
+
+// tourNode is a node of a treap holding an Euler tour, it is either a vertex or an arc along a tree edge. The
+// position of a node in the tour is implicit: the amount of nodes before it in an in-order walk.
+type tourNode struct {
+	left, right, parent *tourNode
+	priority            uint32
+
+	// the vertex this node is, -1 for an arc
+	vertex int
+	flags  uint8
+
+	// aggregates over the subtree
+	size     int
+	vertices int
+	anyFlags uint8
+}
+
+func sizeOf(n *tourNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *tourNode) update() {
+	n.size, n.vertices, n.anyFlags = 1, 0, n.flags
+	if n.vertex >= 0 {
+		n.vertices = 1
+	}
+	for _, c := range []*tourNode{n.left, n.right} {
+		if c != nil {
+			c.parent = n
+			n.size += c.size
+			n.vertices += c.vertices
+			n.anyFlags |= c.anyFlags
+		}
+	}
+}
+
+func (n *tourNode) root() *tourNode {
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+// index returns the position of n in its tour
+func (n *tourNode) index() int {
+	i := sizeOf(n.left)
+	for ; n.parent != nil; n = n.parent {
+		if n == n.parent.right {
+			i += sizeOf(n.parent.left) + 1
+		}
+	}
+	return i
+}
+
+// updateToRoot recalculates the aggregates of n and all its ancestors
+func (n *tourNode) updateToRoot() {
+	for ; n != nil; n = n.parent {
+		n.update()
+	}
+}
+
+// find returns a node with flag set in the tree of root, or nil if there is none
+func find(root *tourNode, flag uint8) *tourNode {
+	n := root
+	if n.anyFlags&flag == 0 {
+		return nil
+	}
+	for {
+		switch {
+		case n.left != nil && n.left.anyFlags&flag != 0:
+			n = n.left
+		case n.flags&flag != 0:
+			return n
+		default:
+			n = n.right
+		}
+	}
+}
+
+func merge(a, b *tourNode) *tourNode {
+	r := mergeTreaps(a, b)
+	if r != nil {
+		r.parent = nil
+	}
+	return r
+}
+
+func mergeTreaps(a, b *tourNode) *tourNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = mergeTreaps(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = mergeTreaps(a, b.left)
+	b.update()
+	return b
+}
+
+// split returns the first k nodes of the tour of t and the rest
+func split(t *tourNode, k int) (*tourNode, *tourNode) {
+	l, r := splitTreap(t, k)
+	if l != nil {
+		l.parent = nil
+	}
+	if r != nil {
+		r.parent = nil
+	}
+	return l, r
+}
+
+func splitTreap(t *tourNode, k int) (l, r *tourNode) {
+	if t == nil {
+		return nil, nil
+	}
+	if sizeOf(t.left) >= k {
+		l, t.left = splitTreap(t.left, k)
+		t.update()
+		return l, t
+	}
+	t.right, r = splitTreap(t.right, k-sizeOf(t.left)-1)
+	t.update()
+	return t, r
+}
+
+type edge struct {
+	a, b int
+}
+
+func newEdge(a, b int) edge {
+	if b < a {
+		a, b = b, a
+	}
+	return edge{a, b}
+}
+
+// eulerTourForest keeps a forest as Euler tours, so trees can be linked, cut and compared in logarithmic time.
+type eulerTourForest struct {
+	vertices map[int]*tourNode
+	// the arcs from a to b and from b to a of every tree edge
+	arcs map[edge][2]*tourNode
+	rand *rand.Rand
+}
+
+func newEulerTourForest(r *rand.Rand) *eulerTourForest {
+	return &eulerTourForest{
+		vertices: make(map[int]*tourNode),
+		arcs:     make(map[edge][2]*tourNode),
+		rand:     r,
+	}
+}
+
+func (f *eulerTourForest) newNode(vertex int) *tourNode {
+	n := &tourNode{priority: f.rand.Uint32(), vertex: vertex}
+	n.update()
+	return n
+}
+
+// vertex returns the node of v, a vertex that has not been seen yet is a tree on its own
+func (f *eulerTourForest) vertex(v int) *tourNode {
+	n, ok := f.vertices[v]
+	if !ok {
+		n = f.newNode(v)
+		f.vertices[v] = n
+	}
+	return n
+}
+
+// reroot rotates the tour of the tree of v to start at v and returns its root
+func (f *eulerTourForest) reroot(v int) *tourNode {
+	n := f.vertex(v)
+	before, after := split(n.root(), n.index())
+	return merge(after, before)
+}
+
+func (f *eulerTourForest) connected(a, b int) bool {
+	return a == b || f.vertex(a).root() == f.vertex(b).root()
+}
+
+// treeSize returns the amount of vertices in the tree of v
+func (f *eulerTourForest) treeSize(v int) int {
+	return f.vertex(v).root().vertices
+}
+
+// link connects the trees of a and b with a tree edge
+func (f *eulerTourForest) link(a, b int) {
+	ta, tb := f.reroot(a), f.reroot(b)
+
+	ab, ba := f.newNode(-1), f.newNode(-1)
+	if a > b {
+		ab, ba = ba, ab
+	}
+	f.arcs[newEdge(a, b)] = [2]*tourNode{ab, ba}
+
+	merge(merge(merge(ta, ab), tb), ba)
+}
+
+// cut removes the tree edge between a and b
+func (f *eulerTourForest) cut(a, b int) {
+	e := newEdge(a, b)
+	arcs := f.arcs[e]
+	delete(f.arcs, e)
+
+	first, second := arcs[0], arcs[1]
+	i, j := first.index(), second.index()
+	if j < i {
+		first, second = second, first
+		i, j = j, i
+	}
+
+	// the tour is: before, first, between, second, after
+	before, rest := split(first.root(), i)
+	_, rest = split(rest, 1)
+	_, rest = split(rest, j-i-1)
+	_, after := split(rest, 1)
+
+	merge(before, after)
+}
+
+func (f *eulerTourForest) setFlag(v int, flag uint8, set bool) {
+	n := f.vertex(v)
+	if set {
+		n.flags |= flag
+	} else {
+		n.flags &^= flag
+	}
+	n.updateToRoot()
+}
+
+// findFlagged returns a vertex in the tree of v with flag set, or -1 if there is none
+func (f *eulerTourForest) findFlagged(v int, flag uint8) int {
+	n := find(f.vertex(v).root(), flag)
+	if n == nil {
+		return -1
+	}
+	return n.vertex
+}
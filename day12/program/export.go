@@ -0,0 +1,97 @@
+package program
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// palette has the colours of the groups, groups share a colour if there are more groups than colours
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b",
+	"#e377c2", "#7f7f7f", "#bcbd22", "#17becf", "#aec7e8", "#ffbb78",
+}
+
+// groupIndex returns the index of the group of every program, -1 for programs that are not in any group
+func groupIndex(g Graph, groups []ProgramSet) map[ProgramId]int {
+	index := make(map[ProgramId]int, len(g.programs))
+	for _, p := range g.programs {
+		index[p] = -1
+	}
+	for i, group := range groups {
+		for p := range group {
+			index[p] = i
+		}
+	}
+	return index
+}
+
+func colourOf(group int) string {
+	if group < 0 {
+		return "#ffffff"
+	}
+	return palette[group%len(palette)]
+}
+
+// pipes returns every pipe once, sorted
+func (g Graph) pipes() (pipes []Pipe) {
+	for _, p := range g.programs {
+		for _, n := range g.neighbours[p] {
+			if p < n {
+				pipes = append(pipes, Pipe{p, n})
+			}
+		}
+	}
+	return
+}
+
+// WriteDOT writes the pipes as an undirected graph in the DOT language of Graphviz, with the programs coloured by
+// their index in groups. Every pipe is a single edge, pipes from a program to itself are left out.
+func WriteDOT(w io.Writer, reports []ReachabilityReport, groups []ProgramSet) error {
+	g := NewGraph(reports)
+	index := groupIndex(g, groups)
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "graph programs {")
+	fmt.Fprintln(&b, "\tnode [style=filled];")
+	for _, p := range g.programs {
+		fmt.Fprintf(&b, "\t%v [fillcolor=%q];\n", p, colourOf(index[p]))
+	}
+	for _, pipe := range g.pipes() {
+		fmt.Fprintf(&b, "\t%v -- %v;\n", pipe.A, pipe.B)
+	}
+	fmt.Fprintln(&b, "}")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteGraphML writes the pipes as an undirected graph in GraphML, with the index in groups, a colour and the amount
+// of pipes of every program as node data. Every pipe is a single edge, pipes from a program to itself are left out.
+func WriteGraphML(w io.Writer, reports []ReachabilityReport, groups []ProgramSet) error {
+	g := NewGraph(reports)
+	index := groupIndex(g, groups)
+
+	var b strings.Builder
+	fmt.Fprintln(&b, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(&b, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(&b, `  <key id="group" for="node" attr.name="group" attr.type="int"/>`)
+	fmt.Fprintln(&b, `  <key id="color" for="node" attr.name="color" attr.type="string"/>`)
+	fmt.Fprintln(&b, `  <key id="degree" for="node" attr.name="degree" attr.type="int"/>`)
+	fmt.Fprintln(&b, `  <graph id="programs" edgedefault="undirected">`)
+	for _, p := range g.programs {
+		fmt.Fprintf(&b, "    <node id=\"p%v\">\n", p)
+		fmt.Fprintf(&b, "      <data key=\"group\">%v</data>\n", index[p])
+		fmt.Fprintf(&b, "      <data key=\"color\">%v</data>\n", colourOf(index[p]))
+		fmt.Fprintf(&b, "      <data key=\"degree\">%v</data>\n", len(g.neighbours[p]))
+		fmt.Fprintln(&b, "    </node>")
+	}
+	for _, pipe := range g.pipes() {
+		fmt.Fprintf(&b, "    <edge source=\"p%v\" target=\"p%v\"/>\n", pipe.A, pipe.B)
+	}
+	fmt.Fprintln(&b, "  </graph>")
+	fmt.Fprintln(&b, "</graphml>")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
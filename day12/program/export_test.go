@@ -0,0 +1,65 @@
+package program
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const exportInput = `0 <-> 2
+1 <-> 1
+2 <-> 0, 2`
+
+func TestWriteDOT(t *testing.T) {
+	reports := parse(t, exportInput)
+
+	var b strings.Builder
+	if err := WriteDOT(&b, reports, DivideIntoReachabilityGroups(reports)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `graph programs {
+	node [style=filled];
+	0 [fillcolor="#1f77b4"];
+	1 [fillcolor="#ff7f0e"];
+	2 [fillcolor="#1f77b4"];
+	0 -- 2;
+}
+`
+	if b.String() != expected {
+		t.Errorf("WriteDOT(...) =\n%v\nbut expected\n%v", b.String(), expected)
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	reports := parse(t, exportInput)
+
+	var b strings.Builder
+	if err := WriteGraphML(&b, reports, DivideIntoReachabilityGroups(reports)); err != nil {
+		t.Fatal(err)
+	}
+
+	var graphml struct {
+		Nodes []struct {
+			Id   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal([]byte(b.String()), &graphml); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(graphml.Nodes) != 3 || graphml.Nodes[1].Id != "p1" || graphml.Nodes[1].Data[0].Value != "1" {
+		t.Errorf("expected 3 nodes with program 1 in group 1, but got %+v", graphml.Nodes)
+	}
+	if len(graphml.Edges) != 1 || graphml.Edges[0].Source != "p0" || graphml.Edges[0].Target != "p2" {
+		t.Errorf("expected a single edge from p0 to p2, but got %+v", graphml.Edges)
+	}
+}
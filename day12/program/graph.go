@@ -0,0 +1,207 @@
+package program
+
+import "sort"
+
+// Pipe connects two programs, A is always the smallest ProgramId.
+type Pipe struct {
+	A, B ProgramId
+}
+
+func newPipe(a, b ProgramId) Pipe {
+	if b < a {
+		a, b = b, a
+	}
+	return Pipe{a, b}
+}
+
+// Graph has the programs as nodes and the pipes between them as edges. Pipes listed from both ends or more than
+// once are a single edge, pipes from a program to itself are ignored.
+type Graph struct {
+	// the neighbours of every program, sorted
+	neighbours map[ProgramId][]ProgramId
+	// all programs, sorted
+	programs []ProgramId
+}
+
+func NewGraph(reports []ReachabilityReport) Graph {
+	pipes := make(map[Pipe]bool)
+	nodes := make(map[ProgramId]bool)
+
+	for _, report := range reports {
+		nodes[report.Program] = true
+		for _, p := range report.CanCommunicateWith {
+			nodes[p] = true
+			if p != report.Program {
+				pipes[newPipe(report.Program, p)] = true
+			}
+		}
+	}
+
+	g := Graph{neighbours: make(map[ProgramId][]ProgramId, len(nodes))}
+	for p := range nodes {
+		g.programs = append(g.programs, p)
+	}
+	sortProgramIds(g.programs)
+
+	for pipe := range pipes {
+		g.neighbours[pipe.A] = append(g.neighbours[pipe.A], pipe.B)
+		g.neighbours[pipe.B] = append(g.neighbours[pipe.B], pipe.A)
+	}
+	for _, n := range g.neighbours {
+		sortProgramIds(n)
+	}
+	return g
+}
+
+func sortProgramIds(ps []ProgramId) {
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i] < ps[j]
+	})
+}
+
+func sortPipes(pipes []Pipe) {
+	sort.Slice(pipes, func(i, j int) bool {
+		if pipes[i].A != pipes[j].A {
+			return pipes[i].A < pipes[j].A
+		}
+		return pipes[i].B < pipes[j].B
+	})
+}
+
+func (g Graph) Programs() []ProgramId {
+	return g.programs
+}
+
+func (g Graph) Neighbours(p ProgramId) []ProgramId {
+	return g.neighbours[p]
+}
+
+// Distances returns the number of pipes between root and every program it can reach.
+func (g Graph) Distances(root ProgramId) map[ProgramId]int {
+	distances, _ := g.bfs(root)
+	return distances
+}
+
+// ShortestPath returns a path with the least amount of pipes from a to b, including both, or nil if b can not be
+// reached from a.
+func (g Graph) ShortestPath(a, b ProgramId) []ProgramId {
+	distances, previous := g.bfs(a)
+
+	if _, ok := distances[b]; !ok {
+		return nil
+	}
+
+	path := make([]ProgramId, distances[b]+1)
+	for i, p := len(path)-1, b; i >= 0; i-- {
+		path[i] = p
+		p = previous[p]
+	}
+	return path
+}
+
+// bfs searches breadth-first from root, previous is the program every program was reached from
+func (g Graph) bfs(root ProgramId) (distances map[ProgramId]int, previous map[ProgramId]ProgramId) {
+	distances = map[ProgramId]int{root: 0}
+	previous = make(map[ProgramId]ProgramId)
+
+	queue := []ProgramId{root}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, n := range g.neighbours[p] {
+			if _, seen := distances[n]; !seen {
+				distances[n] = distances[p] + 1
+				previous[n] = p
+				queue = append(queue, n)
+			}
+		}
+	}
+	return
+}
+
+// Bridges returns the pipes that split a group when they are removed, sorted.
+func (g Graph) Bridges() []Pipe {
+	var bridges []Pipe
+	g.lowLinks(func(p, child ProgramId, isBridge, _ bool) {
+		if isBridge {
+			bridges = append(bridges, newPipe(p, child))
+		}
+	})
+	sortPipes(bridges)
+	return bridges
+}
+
+// ArticulationPoints returns the programs that split a group when they are removed, sorted.
+func (g Graph) ArticulationPoints() []ProgramId {
+	points := make(map[ProgramId]bool)
+	g.lowLinks(func(p, _ ProgramId, _, isArticulation bool) {
+		if isArticulation {
+			points[p] = true
+		}
+	})
+
+	var sorted []ProgramId
+	for p := range points {
+		sorted = append(sorted, p)
+	}
+	sortProgramIds(sorted)
+	return sorted
+}
+
+// CriticalPipes returns the pipes that disconnect a from b when any one of them fails, in order from a to b.
+func (g Graph) CriticalPipes(a, b ProgramId) []Pipe {
+	path := g.ShortestPath(a, b)
+
+	// every path from a to b crosses all bridges separating them, so the shortest does as well
+	bridges := make(map[Pipe]bool)
+	for _, bridge := range g.Bridges() {
+		bridges[bridge] = true
+	}
+
+	var critical []Pipe
+	for i := 1; i < len(path); i++ {
+		if pipe := newPipe(path[i-1], path[i]); bridges[pipe] {
+			critical = append(critical, pipe)
+		}
+	}
+	return critical
+}
+
+// lowLinks runs Tarjan's depth-first search and calls f for every edge of the search tree from p to child, with
+// whether the edge is a bridge and whether p separates child from the rest of the graph.
+func (g Graph) lowLinks(f func(p, child ProgramId, isBridge, isArticulation bool)) {
+	discovery := make(map[ProgramId]int, len(g.programs))
+	low := make(map[ProgramId]int, len(g.programs))
+	time := 0
+
+	var visit func(p, parent ProgramId, isRoot bool)
+	visit = func(p, parent ProgramId, isRoot bool) {
+		time += 1
+		discovery[p], low[p] = time, time
+		children := 0
+
+		for _, n := range g.neighbours[p] {
+			if _, seen := discovery[n]; !seen {
+				children += 1
+				visit(n, p, false)
+
+				if low[n] < low[p] {
+					low[p] = low[n]
+				}
+
+				isArticulation := (isRoot && children > 1) || (!isRoot && low[n] >= discovery[p])
+				f(p, n, low[n] > discovery[p], isArticulation)
+
+			} else if (isRoot || n != parent) && discovery[n] < low[p] {
+				low[p] = discovery[n]
+			}
+		}
+	}
+
+	for _, p := range g.programs {
+		if _, seen := discovery[p]; !seen {
+			visit(p, p, true)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package program
+
+import (
+	"reflect"
+	"testing"
+)
+
+// two triangles 0-1-2 and 3-4-5 joined by the pipe 2-3, with 6 hanging from 5 and 7 on its own
+const graphInput = `0 <-> 1, 2
+1 <-> 0, 2
+2 <-> 0, 1, 3
+3 <-> 2, 4, 5
+4 <-> 3, 5
+5 <-> 3, 4, 6
+6 <-> 5
+7 <-> 7`
+
+func TestGraph_ShortestPath(t *testing.T) {
+	g := NewGraph(parse(t, graphInput))
+
+	if got, expected := g.ShortestPath(0, 6), []ProgramId{0, 2, 3, 5, 6}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ShortestPath(0, 6) = %v, but expected %v", got, expected)
+	}
+	if got, expected := g.ShortestPath(4, 4), []ProgramId{4}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ShortestPath(4, 4) = %v, but expected %v", got, expected)
+	}
+	if got := g.ShortestPath(0, 7); got != nil {
+		t.Errorf("ShortestPath(0, 7) = %v, but expected nil", got)
+	}
+}
+
+func TestGraph_Distances(t *testing.T) {
+	g := NewGraph(parse(t, graphInput))
+
+	expected := map[ProgramId]int{0: 0, 1: 1, 2: 1, 3: 2, 4: 3, 5: 3, 6: 4}
+	if got := g.Distances(0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Distances(0) = %v, but expected %v", got, expected)
+	}
+}
+
+func TestGraph_BridgesAndArticulationPoints(t *testing.T) {
+	g := NewGraph(parse(t, graphInput))
+
+	if got, expected := g.Bridges(), []Pipe{{2, 3}, {5, 6}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Bridges() = %v, but expected %v", got, expected)
+	}
+	if got, expected := g.ArticulationPoints(), []ProgramId{2, 3, 5}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ArticulationPoints() = %v, but expected %v", got, expected)
+	}
+	if got, expected := g.CriticalPipes(0, 6), []Pipe{{2, 3}, {5, 6}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("CriticalPipes(0, 6) = %v, but expected %v", got, expected)
+	}
+	if got := g.CriticalPipes(0, 1); got != nil {
+		t.Errorf("CriticalPipes(0, 1) = %v, but expected none", got)
+	}
+}
+
+func TestGraph_ArticulationPoints_Root(t *testing.T) {
+	// 0 is the root of the search and splits 1 from 2
+	g := NewGraph(parse(t, "0 <-> 1, 2\n1 <-> 0\n2 <-> 0"))
+
+	if got, expected := g.ArticulationPoints(), []ProgramId{0}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ArticulationPoints() = %v, but expected %v", got, expected)
+	}
+}
@@ -1,6 +1,7 @@
 package program
 
 import (
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -28,30 +29,111 @@ func Test_ProgramSet(t *testing.T) {
 	}
 }
 
-func Test_applyReachabilityReport(t *testing.T) {
-	var programSets, expectedProgramSets []ProgramSet
+func TestDisjointSet_Add(t *testing.T) {
+	var expectedProgramSets []ProgramSet
+	s := NewDisjointSet()
 
-	programSets = applyReachabilityReport(programSets, ReachabilityReport{0, []ProgramId{2}})
+	s.Add(ReachabilityReport{0, []ProgramId{2}})
 
 	expectedProgramSets = []ProgramSet{{0: true, 2: true}}
 
-	if !reflect.DeepEqual(programSets, expectedProgramSets) {
-		t.Errorf("applyReachabilityReport 1, expected %v but got %v", expectedProgramSets, programSets)
+	if !reflect.DeepEqual(s.Groups(), expectedProgramSets) {
+		t.Errorf("Add 1, expected %v but got %v", expectedProgramSets, s.Groups())
 	}
 
-	programSets = applyReachabilityReport(programSets, ReachabilityReport{1, []ProgramId{3}})
+	s.Add(ReachabilityReport{1, []ProgramId{3}})
 
 	expectedProgramSets = []ProgramSet{{0: true, 2: true}, {1: true, 3: true}}
 
-	if !reflect.DeepEqual(programSets, expectedProgramSets) {
-		t.Errorf("applyReachabilityReport 2, expected %v but got %v", expectedProgramSets, programSets)
+	if !reflect.DeepEqual(s.Groups(), expectedProgramSets) {
+		t.Errorf("Add 2, expected %v but got %v", expectedProgramSets, s.Groups())
 	}
 
-	programSets = applyReachabilityReport(programSets, ReachabilityReport{5, []ProgramId{2, 1}})
+	if s.SameGroup(0, 3) {
+		t.Errorf("expected 0 and 3 to be in different groups")
+	}
+
+	s.Add(ReachabilityReport{5, []ProgramId{2, 1}})
 
 	expectedProgramSets = []ProgramSet{{0: true, 2: true, 1: true, 3: true, 5: true}}
 
-	if !reflect.DeepEqual(programSets, expectedProgramSets) {
-		t.Errorf("applyReachabilityReport 3, expected %v but got %v", expectedProgramSets, programSets)
+	if !reflect.DeepEqual(s.Groups(), expectedProgramSets) {
+		t.Errorf("Add 3, expected %v but got %v", expectedProgramSets, s.Groups())
+	}
+
+	if !s.SameGroup(0, 3) {
+		t.Errorf("expected 0 and 3 to be in the same group")
+	}
+
+	expectedSizes := map[ProgramId]int{s.Find(0): 5}
+	if !reflect.DeepEqual(s.GroupSizes(), expectedSizes) {
+		t.Errorf("GroupSizes(), expected %v but got %v", expectedSizes, s.GroupSizes())
+	}
+}
+
+// syntheticReports returns reports of n programs linked to a few random other programs, with every link listed
+// from both ends.
+func syntheticReports(n int, seed int64) []ReachabilityReport {
+	r := rand.New(rand.NewSource(seed))
+
+	reports := make([]ReachabilityReport, n)
+	for i := range reports {
+		reports[i].Program = ProgramId(i)
+	}
+	for i := range reports {
+		for links := r.Intn(2); links > 0; links-- {
+			j := r.Intn(n)
+			reports[i].CanCommunicateWith = append(reports[i].CanCommunicateWith, ProgramId(j))
+			reports[j].CanCommunicateWith = append(reports[j].CanCommunicateWith, ProgramId(i))
+		}
+	}
+	return reports
+}
+
+// chainReports returns reports of n programs linked in a single line.
+func chainReports(n int) []ReachabilityReport {
+	reports := make([]ReachabilityReport, n)
+	for i := range reports {
+		reports[i].Program = ProgramId(i)
+		if i > 0 {
+			reports[i].CanCommunicateWith = append(reports[i].CanCommunicateWith, ProgramId(i-1))
+		}
+		if i < n-1 {
+			reports[i].CanCommunicateWith = append(reports[i].CanCommunicateWith, ProgramId(i+1))
+		}
+	}
+	return reports
+}
+
+func BenchmarkDivideIntoReachabilityGroups_Random100k(b *testing.B) {
+	reports := syntheticReports(100000, 1)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		DivideIntoReachabilityGroups(reports)
+	}
+}
+
+func BenchmarkDisjointSet_Add_Random100k(b *testing.B) {
+	reports := syntheticReports(100000, 1)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := NewDisjointSet()
+		for _, report := range reports {
+			s.Add(report)
+		}
+	}
+}
+
+func BenchmarkDisjointSet_Add_Chain100k(b *testing.B) {
+	reports := chainReports(100000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := NewDisjointSet()
+		for _, report := range reports {
+			s.Add(report)
+		}
 	}
 }
@@ -2,6 +2,8 @@ package program
 
 import (
 	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/util"
+	"github.com/pkg/errors"
 	"strconv"
 	"strings"
 )
@@ -11,38 +13,53 @@ type ReachabilityReport struct {
 	CanCommunicateWith []ProgramId
 }
 
-func parseReachabilityReport(line string) ReachabilityReport {
-	var report ReachabilityReport
+func parseReachabilityReport(line string) (report ReachabilityReport, err error) {
+	parts := strings.Split(line, " <-> ")
+	if len(parts) != 2 {
+		return report, errors.New(fmt.Sprintf("could not parse input %q", line))
+	}
 
-	_, err := fmt.Sscanf(line, "%d <-> ", &report.Program)
+	p, err := strconv.Atoi(strings.TrimSpace(parts[0]))
 	if err != nil {
-		panic(fmt.Sprintf("could not parse input %q", line))
+		return report, errors.New(fmt.Sprintf("could not convert \"%v\" to integer", parts[0]))
 	}
+	report.Program = ProgramId(p)
 
-	indexOfDivider := strings.Index(line, "<-> ")
-	if indexOfDivider > 0 {
-		listOfIds := strings.Split(line[indexOfDivider+4:], ", ")
-		for _, pString := range listOfIds {
-			p, err := strconv.Atoi(pString)
-			if err != nil {
-				panic(fmt.Sprintf("could not convert \"%v\" to integer", pString))
-			}
-
-			report.CanCommunicateWith = append(report.CanCommunicateWith, ProgramId(p))
+	for _, pString := range strings.Split(parts[1], ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(pString))
+		if err != nil {
+			return report, errors.New(fmt.Sprintf("could not convert \"%v\" to integer", pString))
 		}
+
+		report.CanCommunicateWith = append(report.CanCommunicateWith, ProgramId(p))
 	}
 
-	return report
+	return report, nil
 }
 
-func ParseListOfReachabilityReports(input string) []ReachabilityReport {
+// ParseListOfReachabilityReports parses a report per line, blank lines are skipped. If any line can not be parsed,
+// the error is a util.LineErrors listing all of them.
+func ParseListOfReachabilityReports(input string) ([]ReachabilityReport, error) {
 	lines := strings.Split(input, "\n")
 
 	reports := make([]ReachabilityReport, 0, len(lines))
+	var parseErrors util.LineErrors
 
-	for _, line := range lines {
-		reports = append(reports, parseReachabilityReport(line))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		report, err := parseReachabilityReport(line)
+		if err != nil {
+			parseErrors = append(parseErrors, util.LineError{Line: i + 1, Err: err})
+			continue
+		}
+		reports = append(reports, report)
 	}
 
-	return reports
+	if len(parseErrors) > 0 {
+		return reports, parseErrors
+	}
+	return reports, nil
 }
@@ -1,6 +1,7 @@
 package program
 
 import (
+	"github.com/koenaad/Advent-of-Code-2017/util"
 	"reflect"
 	"testing"
 )
@@ -19,7 +20,10 @@ func Test_parseReachabilityReport(t *testing.T) {
 		{"6 <-> 4, 5", ReachabilityReport{6, []ProgramId{4, 5}}},
 	}
 	for _, c := range cases {
-		report := parseReachabilityReport(c.input)
+		report, err := parseReachabilityReport(c.input)
+		if err != nil {
+			t.Errorf("parseReachabilityReport(%q): unexpected error %v", c.input, err)
+		}
 		if !reflect.DeepEqual(c.expectedReport, report) {
 			t.Errorf("parseReachabilityReport(%q): got %v, but expected %v", c.input, report, c.expectedReport)
 		}
@@ -45,8 +49,36 @@ func TestParseListOfReachabilityReports(t *testing.T) {
 		{6, []ProgramId{4, 5}},
 	}
 
-	got := ParseListOfReachabilityReports(input)
+	got := parse(t, input)
 	if !reflect.DeepEqual(expectedReports, got) {
 		t.Errorf("ParseListOfReachabilityReports(%q): got %v, but expected %v", input, got, expectedReports)
 	}
 }
+
+func TestParseListOfReachabilityReports_Errors(t *testing.T) {
+	input := `0 <-> 2
+1 -> 1
+
+2 <-> 0, x
+3 <-> 2
+`
+
+	reports, err := ParseListOfReachabilityReports(input)
+
+	if len(reports) != 2 {
+		t.Errorf("expected 2 valid reports, but got %v", reports)
+	}
+
+	parseErrors, ok := err.(util.LineErrors)
+	if !ok || len(parseErrors) != 2 || parseErrors[0].Line != 2 || parseErrors[1].Line != 4 {
+		t.Errorf("expected errors on line 2 and 4, but got %v", err)
+	}
+}
+
+func parse(t *testing.T, input string) []ReachabilityReport {
+	reports, err := ParseListOfReachabilityReports(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reports
+}
@@ -0,0 +1,141 @@
+package program
+
+import (
+	"fmt"
+	"strings"
+)
+
+type IssueKind int
+
+const (
+	// AsymmetricLink is a program listing Other, while Other does not list the program
+	AsymmetricLink IssueKind = iota
+	// DuplicateProgram is a program with more than one report
+	DuplicateProgram
+	// UndeclaredProgram is a program without report, listed by Other
+	UndeclaredProgram
+	// SelfLoop is a program listing itself
+	SelfLoop
+)
+
+type Issue struct {
+	Kind    IssueKind
+	Program ProgramId
+	Other   ProgramId
+}
+
+func (i Issue) String() string {
+	switch i.Kind {
+	case AsymmetricLink:
+		return fmt.Sprintf("program %v can communicate with %v, but not the other way around", i.Program, i.Other)
+	case DuplicateProgram:
+		return fmt.Sprintf("program %v is reported more than once", i.Program)
+	case UndeclaredProgram:
+		return fmt.Sprintf("program %v is listed by %v, but never reported", i.Program, i.Other)
+	case SelfLoop:
+		return fmt.Sprintf("program %v can communicate with itself", i.Program)
+	default:
+		return fmt.Sprintf("unknown issue %v", int(i.Kind))
+	}
+}
+
+// ValidationError lists the issues that made strict validation reject the reports.
+type ValidationError []Issue
+
+func (e ValidationError) Error() string {
+	var lines []string
+	for _, issue := range e {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type ValidationMode int
+
+const (
+	// Strict rejects reports with any issue
+	Strict ValidationMode = iota
+	// Lenient repairs the reports: duplicate reports are merged, programs no longer list themselves, every listed
+	// program gets a report and every program lists the programs listing it.
+	Lenient
+)
+
+// Validate checks whether the reports describe pipes that work in both directions. In strict mode, the reports are
+// returned as is, with a ValidationError if there are any issues. In lenient mode, the reports are returned repaired.
+func Validate(reports []ReachabilityReport, mode ValidationMode) ([]ReachabilityReport, []Issue, error) {
+	var issues []Issue
+
+	// merge duplicate reports, without changing the original
+	var repaired []ReachabilityReport
+	index := make(map[ProgramId]int)
+
+	for _, report := range reports {
+		i, ok := index[report.Program]
+		if ok {
+			issues = append(issues, Issue{DuplicateProgram, report.Program, report.Program})
+		} else {
+			i = len(repaired)
+			index[report.Program] = i
+			repaired = append(repaired, ReachabilityReport{Program: report.Program})
+		}
+		repaired[i].CanCommunicateWith = append(repaired[i].CanCommunicateWith, report.CanCommunicateWith...)
+	}
+
+	// remove self-loops and duplicate links
+	links := make([]ProgramSet, len(repaired))
+	for i := range repaired {
+		r := &repaired[i]
+		links[i] = newProgramSet()
+
+		var list []ProgramId
+		selfLoop := false
+		for _, p := range r.CanCommunicateWith {
+			if p == r.Program {
+				selfLoop = true
+				continue
+			}
+			if !links[i].Contains(p) {
+				links[i].add(p)
+				list = append(list, p)
+			}
+		}
+		if selfLoop {
+			issues = append(issues, Issue{SelfLoop, r.Program, r.Program})
+		}
+		r.CanCommunicateWith = list
+	}
+
+	// add missing reports and links, only the links of the original reports are checked
+	declared := len(repaired)
+	for i := 0; i < declared; i++ {
+		p := repaired[i].Program
+
+		for _, other := range repaired[i].CanCommunicateWith {
+			j, ok := index[other]
+			if !ok {
+				issues = append(issues, Issue{UndeclaredProgram, other, p})
+
+				j = len(repaired)
+				index[other] = j
+				repaired = append(repaired, ReachabilityReport{Program: other})
+				links = append(links, newProgramSet())
+
+			} else if j < declared && !links[j].Contains(p) {
+				issues = append(issues, Issue{AsymmetricLink, p, other})
+			}
+
+			if !links[j].Contains(p) {
+				links[j].add(p)
+				repaired[j].CanCommunicateWith = append(repaired[j].CanCommunicateWith, p)
+			}
+		}
+	}
+
+	if mode == Strict {
+		if len(issues) > 0 {
+			return reports, issues, ValidationError(issues)
+		}
+		return reports, nil, nil
+	}
+	return repaired, issues, nil
+}
@@ -0,0 +1,65 @@
+package program
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	reports := parse(t, `0 <-> 1, 2
+1 <-> 0, 1
+2 <-> 3
+0 <-> 4
+3 <-> 2, 5`)
+
+	expectedIssues := []Issue{
+		{DuplicateProgram, 0, 0},
+		{SelfLoop, 1, 1},
+		{AsymmetricLink, 0, 2},
+		{UndeclaredProgram, 4, 0},
+		{UndeclaredProgram, 5, 3},
+	}
+
+	got, issues, err := Validate(reports, Strict)
+	if !reflect.DeepEqual(got, reports) {
+		t.Errorf("strict: expected the reports to be unchanged, but got %v", got)
+	}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("strict: expected issues %v, but got %v", expectedIssues, issues)
+	}
+	if !reflect.DeepEqual(err, ValidationError(expectedIssues)) {
+		t.Errorf("strict: expected a ValidationError, but got %v", err)
+	}
+
+	got, issues, err = Validate(reports, Lenient)
+	if err != nil {
+		t.Errorf("lenient: unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("lenient: expected issues %v, but got %v", expectedIssues, issues)
+	}
+
+	expectedReports := []ReachabilityReport{
+		{0, []ProgramId{1, 2, 4}},
+		{1, []ProgramId{0}},
+		{2, []ProgramId{3, 0}},
+		{3, []ProgramId{2, 5}},
+		{4, []ProgramId{0}},
+		{5, []ProgramId{3}},
+	}
+	if !reflect.DeepEqual(got, expectedReports) {
+		t.Errorf("lenient: expected reports %v, but got %v", expectedReports, got)
+	}
+
+	if _, issues, _ := Validate(got, Strict); issues != nil {
+		t.Errorf("expected the repaired reports to be valid, but got %v", issues)
+	}
+}
+
+func TestValidate_Valid(t *testing.T) {
+	reports := parse(t, "0 <-> 2\n2 <-> 0")
+
+	if _, issues, err := Validate(reports, Strict); issues != nil || err != nil {
+		t.Errorf("expected no issues, but got %v, %v", issues, err)
+	}
+}
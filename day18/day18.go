@@ -1,11 +1,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"os"
 )
 
+var trace = flag.String("trace", "", "trace execution to stderr, either \"text\" or \"json\"")
+
 func main() {
+	flag.Parse()
+
 	fmt.Println("Advent of Code 2017 - day 18")
 
 	instructions, err := duet.ParseInstructions(input)
@@ -20,32 +26,31 @@ func main() {
 	fmt.Printf("Puzzle 2: amount of values sent by program 1 = %v\n", valuesSentByProgram1)
 }
 
+func options() []duet.Option {
+	switch *trace {
+	case "text":
+		return []duet.Option{duet.WithTracer(duet.NewTextTracer(os.Stderr))}
+	case "json":
+		return []duet.Option{duet.WithTracer(duet.NewJSONTracer(os.Stderr))}
+	default:
+		return nil
+	}
+}
+
 func findLastPlayedFrequency(instructions []duet.Instruction) int {
-	c := duet.NewControllerV1()
+	c := duet.NewControllerV1(options()...)
 	c.Run(instructions)
 	return c.LastFrequency
 }
 
 func valuesSentByProgram1(instructions []duet.Instruction) int {
-	chanSize := 1000
-
-	chan1 := make(chan int, chanSize)
-	defer close(chan1)
-
-	chan2 := make(chan int, chanSize)
-	defer close(chan2)
-
-	c0, done0 := duet.NewControllerV2(chan1, chan2, 0)
-	go c0.Run(instructions)
-
-	c1, done1 := duet.NewControllerV2(chan2, chan1, 1)
-	go c1.Run(instructions)
-
-	// block until controller 0 and 1 is done
-	<-done0
-	<-done1
+	n, err := duet.NewNetwork(instructions, duet.Pairwise(2), options()...)
+	if err != nil {
+		panic(err)
+	}
 
-	return c1.ValuesSent
+	stats := n.Run()
+	return stats[1].ValuesSent
 }
 
 const input = `set i 31
@@ -49,3 +49,31 @@ rcv d`
 		t.Errorf("valuesSentByProgram1(...) = %v, but expected %v", got, expected)
 	}
 }
+
+func BenchmarkController_Run(b *testing.B) {
+	instructions, err := duet.ParseInstructions(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		c := duet.NewControllerV1()
+		c.Run(instructions)
+	}
+}
+
+func BenchmarkProgram_Run(b *testing.B) {
+	instructions, err := duet.ParseInstructions(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+	program, err := duet.Compile(instructions)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		c := duet.NewControllerV1()
+		program.Run(&c)
+	}
+}
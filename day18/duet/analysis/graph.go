@@ -0,0 +1,168 @@
+package analysis
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+)
+
+// Block is a basic block: a range of instructions [Start, End) that is always executed from start to end.
+type Block struct {
+	Start, End int
+	Succs      []int
+	Preds      []int
+	// the block ends in a jump with an offset read from a register, its successors are all blocks
+	DynamicJump bool
+	// the program can halt after this block by running past either end of the program
+	Exits bool
+}
+
+// Graph is the control-flow graph of a program, block 0 is the entry.
+type Graph struct {
+	instructions []duet.Instruction
+	Blocks       []Block
+	blockOf      []int
+	reachable    []bool
+}
+
+// jumpKind tells whether a conditional jump is taken, judged from its condition alone
+type jumpKind uint8
+
+const (
+	notAJump jumpKind = iota
+	maybeJumps
+	alwaysJumps
+	neverJumps
+)
+
+func classifyJump(ir duet.Instruction) jumpKind {
+	var taken func(v int) bool
+
+	switch ir.Opcode() {
+	case "jgz":
+		taken = func(v int) bool { return v > 0 }
+	case "jnz":
+		taken = func(v int) bool { return v != 0 }
+	default:
+		return notAJump
+	}
+
+	cond := ir.Operands()[0]
+	if cond.IsRegister() {
+		return maybeJumps
+	}
+	if taken(cond.Value) {
+		return alwaysJumps
+	}
+	return neverJumps
+}
+
+// constantTarget returns the instruction a jump lands on if its offset is a constant.
+func constantTarget(instructions []duet.Instruction, i int) (target int, ok bool) {
+	offset := instructions[i].Operands()[1]
+	if offset.IsRegister() {
+		return 0, false
+	}
+	return i + offset.Value, true
+}
+
+func BuildGraph(instructions []duet.Instruction) Graph {
+	g := Graph{instructions: instructions}
+	n := len(instructions)
+
+	if n == 0 {
+		return g
+	}
+
+	leader := make([]bool, n)
+	leader[0] = true
+
+	for i, ir := range instructions {
+		if classifyJump(ir) == notAJump {
+			continue
+		}
+		if i+1 < n {
+			leader[i+1] = true
+		}
+		if target, ok := constantTarget(instructions, i); ok && target >= 0 && target < n {
+			leader[target] = true
+		}
+	}
+
+	g.blockOf = make([]int, n)
+	for i := 0; i < n; i++ {
+		if leader[i] {
+			g.Blocks = append(g.Blocks, Block{Start: i})
+		}
+		g.blockOf[i] = len(g.Blocks) - 1
+		g.Blocks[len(g.Blocks)-1].End = i + 1
+	}
+
+	for b := range g.Blocks {
+		block := &g.Blocks[b]
+		last := block.End - 1
+
+		addEdgeTo := func(i int) {
+			if i < 0 || i >= n {
+				block.Exits = true
+				return
+			}
+			block.Succs = appendUnique(block.Succs, g.blockOf[i])
+		}
+
+		kind := classifyJump(instructions[last])
+
+		if kind != alwaysJumps {
+			addEdgeTo(last + 1)
+		}
+		if kind == maybeJumps || kind == alwaysJumps {
+			if target, ok := constantTarget(instructions, last); ok {
+				addEdgeTo(target)
+			} else {
+				block.DynamicJump = true
+				block.Exits = true
+				for succ := range g.Blocks {
+					block.Succs = appendUnique(block.Succs, succ)
+				}
+			}
+		}
+	}
+
+	for b, block := range g.Blocks {
+		for _, succ := range block.Succs {
+			g.Blocks[succ].Preds = append(g.Blocks[succ].Preds, b)
+		}
+	}
+
+	g.reachable = make([]bool, len(g.Blocks))
+	queue := []int{0}
+	g.reachable[0] = true
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		for _, succ := range g.Blocks[b].Succs {
+			if !g.reachable[succ] {
+				g.reachable[succ] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	return g
+}
+
+// BlockOf returns the index of the block containing instruction i.
+func (g *Graph) BlockOf(i int) int {
+	return g.blockOf[i]
+}
+
+func (g *Graph) Reachable(block int) bool {
+	return g.reachable[block]
+}
+
+func appendUnique(s []int, v int) []int {
+	for _, e := range s {
+		if e == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"reflect"
+	"testing"
+)
+
+const testProgram = `set a 3
+add b a
+add a -1
+jnz a -2
+jgz 1 3
+set c 1
+snd c
+snd b
+jgz b -20`
+
+func buildTestGraph(t *testing.T, input string) Graph {
+	instructions, err := duet.ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BuildGraph(instructions)
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := buildTestGraph(t, testProgram)
+
+	expected := []Block{
+		{Start: 0, End: 1, Succs: []int{1}},
+		{Start: 1, End: 4, Succs: []int{2, 1}, Preds: []int{0, 1}},
+		{Start: 4, End: 5, Succs: []int{4}, Preds: []int{1}},
+		{Start: 5, End: 7, Succs: []int{4}},
+		{Start: 7, End: 9, Preds: []int{2, 3}, Exits: true},
+	}
+	if !reflect.DeepEqual(g.Blocks, expected) {
+		t.Errorf("BuildGraph(...).Blocks = %+v, but expected %+v", g.Blocks, expected)
+	}
+
+	for b, expected := range []bool{true, true, true, false, true} {
+		if g.Reachable(b) != expected {
+			t.Errorf("Reachable(%v) = %v, but expected %v", b, g.Reachable(b), expected)
+		}
+	}
+}
+
+func TestBuildGraph_DynamicJump(t *testing.T) {
+	g := buildTestGraph(t, `set a 1
+jgz a p
+snd a`)
+
+	if len(g.Blocks) != 2 || !g.Blocks[0].DynamicJump || !reflect.DeepEqual(g.Blocks[0].Succs, []int{1, 0}) {
+		t.Errorf("expected a dynamic jump to every block, but got %+v", g.Blocks)
+	}
+}
+
+func TestGraph_Loops(t *testing.T) {
+	g := buildTestGraph(t, testProgram)
+
+	expected := []Loop{{Header: 1, Latches: []int{1}, Blocks: []int{1}}}
+	if got := g.Loops(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Loops() = %+v, but expected %+v", got, expected)
+	}
+
+	g = buildTestGraph(t, `set i 3
+set j 2
+add j -1
+jgz j -1
+add i -1
+jgz i -4`)
+
+	expected = []Loop{
+		{Header: 1, Latches: []int{3}, Blocks: []int{1, 2, 3}},
+		{Header: 2, Latches: []int{2}, Blocks: []int{2}},
+	}
+	if got := g.Loops(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Loops() = %+v, but expected %+v", got, expected)
+	}
+}
+
+func TestGraph_ReadBeforeWritten(t *testing.T) {
+	g := buildTestGraph(t, testProgram)
+
+	expected := []string{"b"}
+	if got := g.ReadBeforeWritten(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ReadBeforeWritten() = %v, but expected %v", got, expected)
+	}
+}
+
+func TestGraph_Warnings(t *testing.T) {
+	g := buildTestGraph(t, testProgram)
+
+	expected := []Warning{
+		{DeadCode, 5, "instructions 5 to 6 are unreachable"},
+		{JumpOutOfBounds, 8, "jump to -12 is outside of the program"},
+	}
+	if got := g.Warnings(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Warnings() = %v, but expected %v", got, expected)
+	}
+}
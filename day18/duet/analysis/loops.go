@@ -0,0 +1,109 @@
+package analysis
+
+import "sort"
+
+// Loop is a natural loop: the header dominates every block in the loop and is jumped back to from the latches.
+type Loop struct {
+	Header  int
+	Latches []int
+	Blocks  []int
+}
+
+// dominators returns for every reachable block which blocks dominate it.
+func (g *Graph) dominators() [][]bool {
+	n := len(g.Blocks)
+	dom := make([][]bool, n)
+
+	for b := range dom {
+		dom[b] = make([]bool, n)
+		if !g.reachable[b] {
+			continue
+		}
+		for d := range dom[b] {
+			dom[b][d] = b != 0 || d == 0
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for b := 1; b < n; b++ {
+			if !g.reachable[b] {
+				continue
+			}
+			for d := range dom[b] {
+				if d == b || !dom[b][d] {
+					continue
+				}
+				for _, p := range g.Blocks[b].Preds {
+					if g.reachable[p] && !dom[p][d] {
+						dom[b][d] = false
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return dom
+}
+
+func (g *Graph) Loops() (loops []Loop) {
+	dom := g.dominators()
+	byHeader := make(map[int]*Loop)
+	var headers []int
+
+	for b, block := range g.Blocks {
+		if !g.reachable[b] || block.DynamicJump {
+			continue
+		}
+		for _, h := range block.Succs {
+			if !dom[b][h] {
+				continue
+			}
+
+			loop, ok := byHeader[h]
+			if !ok {
+				loop = &Loop{Header: h, Blocks: []int{h}}
+				byHeader[h] = loop
+				headers = append(headers, h)
+			}
+			loop.Latches = appendUnique(loop.Latches, b)
+
+			// walk backwards from the latch until the header to collect the body
+			stack := []int{b}
+			for len(stack) > 0 {
+				x := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				if containsInt(loop.Blocks, x) {
+					continue
+				}
+				loop.Blocks = append(loop.Blocks, x)
+				for _, p := range g.Blocks[x].Preds {
+					if g.reachable[p] {
+						stack = append(stack, p)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Ints(headers)
+	for _, h := range headers {
+		loop := byHeader[h]
+		sort.Ints(loop.Blocks)
+		sort.Ints(loop.Latches)
+		loops = append(loops, *loop)
+	}
+	return
+}
+
+func containsInt(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"sort"
+)
+
+// effects returns the registers an instruction reads and writes. rcv is treated as in the second version of the
+// controller, where it writes the received value without reading the register.
+func effects(ir duet.Instruction) (reads, writes []string) {
+	operands := ir.Operands()
+
+	read := func(o duet.Operand) {
+		if o.IsRegister() {
+			reads = append(reads, o.Register)
+		}
+	}
+
+	switch ir.Opcode() {
+	case "set":
+		read(operands[1])
+		writes = append(writes, operands[0].Register)
+	case "rcv":
+		writes = append(writes, operands[0].Register)
+	case "add", "sub", "mul", "mod":
+		read(operands[0])
+		read(operands[1])
+		writes = append(writes, operands[0].Register)
+	default:
+		for _, o := range operands {
+			read(o)
+		}
+	}
+	return
+}
+
+// ReadBeforeWritten returns the registers that can be read on some path from the start of the program before
+// anything is written to them, these depend on the initial state of the controller.
+func (g *Graph) ReadBeforeWritten() []string {
+	if len(g.Blocks) == 0 {
+		return nil
+	}
+
+	n := len(g.Blocks)
+	use := make([]map[string]bool, n)
+	def := make([]map[string]bool, n)
+	liveIn := make([]map[string]bool, n)
+
+	for b, block := range g.Blocks {
+		use[b] = make(map[string]bool)
+		def[b] = make(map[string]bool)
+		liveIn[b] = make(map[string]bool)
+
+		for _, ir := range g.instructions[block.Start:block.End] {
+			reads, writes := effects(ir)
+			for _, r := range reads {
+				if !def[b][r] {
+					use[b][r] = true
+				}
+			}
+			for _, r := range writes {
+				def[b][r] = true
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for b := n - 1; b >= 0; b-- {
+			if !g.reachable[b] {
+				continue
+			}
+
+			in := make(map[string]bool)
+			for r := range use[b] {
+				in[r] = true
+			}
+			for _, succ := range g.Blocks[b].Succs {
+				for r := range liveIn[succ] {
+					if !def[b][r] {
+						in[r] = true
+					}
+				}
+			}
+
+			if len(in) != len(liveIn[b]) {
+				liveIn[b] = in
+				changed = true
+			}
+		}
+	}
+
+	var registers []string
+	for r := range liveIn[0] {
+		registers = append(registers, r)
+	}
+	sort.Strings(registers)
+	return registers
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import "fmt"
+
+type WarningKind uint8
+
+const (
+	DeadCode WarningKind = iota
+	JumpOutOfBounds
+)
+
+type Warning struct {
+	Kind        WarningKind
+	Instruction int
+	Message     string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%v: %v", w.Instruction, w.Message)
+}
+
+// Warnings reports unreachable instructions and jumps to a constant target outside of the program. Jumping to the
+// instruction right after the last one is the usual way to end a program and is not reported.
+func (g *Graph) Warnings() (warnings []Warning) {
+	n := len(g.instructions)
+
+	for b, block := range g.Blocks {
+		if !g.reachable[b] {
+			// report a run of unreachable blocks only once
+			if b > 0 && !g.reachable[b-1] {
+				continue
+			}
+			end := block.End
+			for next := b + 1; next < len(g.Blocks) && !g.reachable[next]; next++ {
+				end = g.Blocks[next].End
+			}
+			warnings = append(warnings, Warning{DeadCode, block.Start, fmt.Sprintf("instructions %v to %v are unreachable", block.Start, end-1)})
+			continue
+		}
+
+		last := block.End - 1
+		kind := classifyJump(g.instructions[last])
+		if kind != maybeJumps && kind != alwaysJumps {
+			continue
+		}
+		if target, ok := constantTarget(g.instructions, last); ok && (target < 0 || target > n) {
+			warnings = append(warnings, Warning{JumpOutOfBounds, last, fmt.Sprintf("jump to %v is outside of the program", target)})
+		}
+	}
+	return
+}
@@ -3,19 +3,46 @@ package duet
 import (
 	"fmt"
 	"github.com/pkg/errors"
+	"math/big"
 	"strconv"
+	"unicode"
 )
 
+// asReg accepts a letter followed by any amount of letters, digits and underscores as register name
 func asReg(s string) (r register, err error) {
-	if len(s) != 1 {
-		return r, errors.New(fmt.Sprintf("could not parse argument %q as register: length is not 1", s))
+	if s == "" {
+		return r, errors.New("could not parse empty argument as register")
 	}
-	return register([]rune(s)[0]), nil
+	for i, c := range s {
+		if !unicode.IsLetter(c) && (i == 0 || (!unicode.IsDigit(c) && c != '_')) {
+			return r, errors.New(fmt.Sprintf("could not parse argument %q as register: invalid character %q", s, c))
+		}
+	}
+	return register(s), nil
 }
 
 // value can either be a constant value or a reference to a value stored in a register to be resolved at run-time
 type value interface {
 	fetch(c *Controller) int
+	fetchBig(c *Controller) *big.Int
+	operand() Operand
+}
+
+// Operand is an argument of an instruction as written in the program, either a register or a constant.
+type Operand struct {
+	Register string
+	Value    int
+}
+
+func (o Operand) IsRegister() bool {
+	return o.Register != ""
+}
+
+func (o Operand) String() string {
+	if o.IsRegister() {
+		return o.Register
+	}
+	return strconv.Itoa(o.Value)
 }
 
 func asValue(s string) (v value, err error) {
@@ -36,50 +63,111 @@ func (v valueInt) fetch(c *Controller) int {
 	return int(v)
 }
 
+func (v valueInt) fetchBig(c *Controller) *big.Int {
+	return big.NewInt(int64(v))
+}
+
+func (v valueInt) operand() Operand {
+	return Operand{Value: int(v)}
+}
+
 type valueReg register
 
 func (v valueReg) fetch(c *Controller) int {
 	return c.get(register(v))
 }
 
-func parseAsReg(args []string) (reg register, err error) {
-	if len(args) != 1 {
-		err = errors.New("expecting 1 argument")
+func (v valueReg) fetchBig(c *Controller) *big.Int {
+	return c.getBig(register(v))
+}
+
+func (v valueReg) operand() Operand {
+	return register(v).operand()
+}
+
+func (r register) operand() Operand {
+	return Operand{Register: string(r)}
+}
+
+// field is a whitespace separated word on a line, column is 1-based and counted in runes
+type field struct {
+	text   string
+	column int
+}
+
+// fieldError is an error caused by a specific field on a line
+type fieldError struct {
+	column int
+	err    error
+}
+
+func (e fieldError) Error() string {
+	return e.err.Error()
+}
+
+func errorAt(f field, err error) error {
+	return fieldError{f.column, err}
+}
+
+func expectArgs(args []field, n int) error {
+	if len(args) == n {
+		return nil
+	}
+
+	msg := "expecting 1 argument"
+	if n != 1 {
+		msg = fmt.Sprintf("expecting %v arguments", n)
+	}
+	if len(args) > n {
+		return errorAt(args[n], errors.New(msg))
+	}
+	return errors.New(msg)
+}
+
+func parseAsReg(args []field) (reg register, err error) {
+	if err = expectArgs(args, 1); err != nil {
 		return
 	}
-	return asReg(args[0])
+	if reg, err = asReg(args[0].text); err != nil {
+		err = errorAt(args[0], err)
+	}
+	return
 }
 
-func parseAsVal(args []string) (val value, err error) {
-	if len(args) != 1 {
-		err = errors.New("expecting 1 argument")
+func parseAsVal(args []field) (val value, err error) {
+	if err = expectArgs(args, 1); err != nil {
 		return
 	}
-	return asValue(args[0])
+	if val, err = asValue(args[0].text); err != nil {
+		err = errorAt(args[0], err)
+	}
+	return
 }
 
-func parseAsRegAndVal(args []string) (reg register, val value, err error) {
-	if len(args) != 2 {
-		err = errors.New("expecting 2 argument")
+func parseAsRegAndVal(args []field) (reg register, val value, err error) {
+	if err = expectArgs(args, 2); err != nil {
 		return
 	}
-	reg, err = asReg(args[0])
-	if err != nil {
+	if reg, err = asReg(args[0].text); err != nil {
+		err = errorAt(args[0], err)
 		return
 	}
-	val, err = asValue(args[1])
+	if val, err = asValue(args[1].text); err != nil {
+		err = errorAt(args[1], err)
+	}
 	return
 }
 
-func parseAsValAndVal(args []string) (val1 value, val2 value, err error) {
-	if len(args) != 2 {
-		err = errors.New("expecting 2 argument")
+func parseAsValAndVal(args []field) (val1 value, val2 value, err error) {
+	if err = expectArgs(args, 2); err != nil {
 		return
 	}
-	val1, err = asValue(args[0])
-	if err != nil {
+	if val1, err = asValue(args[0].text); err != nil {
+		err = errorAt(args[0], err)
 		return
 	}
-	val2, err = asValue(args[1])
+	if val2, err = asValue(args[1].text); err != nil {
+		err = errorAt(args[1], err)
+	}
 	return
 }
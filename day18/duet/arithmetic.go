@@ -0,0 +1,178 @@
+package duet
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// ArithmeticError describes the instruction a controller halted on because of an overflow or a division by zero.
+type ArithmeticError struct {
+	Index  int
+	Opcode string
+	Reason HaltReason
+}
+
+func (e ArithmeticError) Error() string {
+	return fmt.Sprintf("instruction %v (%v): %v", e.Index, e.Opcode, e.Reason)
+}
+
+// WithBigInt stores registers as arbitrary-precision integers, so add, sub and mul never overflow. Values that are
+// sent, received, played or used as jump offset still have to fit in an int.
+func WithBigInt() Option {
+	return func(c *Controller) {
+		if c.big {
+			return
+		}
+		c.big = true
+		c.bigRegisters = make(map[register]*big.Int, len(c.registers))
+		for r, v := range c.registers {
+			c.bigRegisters[r] = big.NewInt(int64(v))
+		}
+		c.registers = nil
+	}
+}
+
+// calculate applies an arithmetic opcode on x and y, reporting overflows and divisions by zero instead of
+// silently wrapping around.
+func calculate(code opcode, x, y int) (int, HaltReason) {
+	switch code {
+	case opAdd:
+		r := x + y
+		if (x >= 0) == (y >= 0) && (r >= 0) != (x >= 0) {
+			return 0, Overflow
+		}
+		return r, NotHalted
+	case opSub:
+		r := x - y
+		if (x >= 0) != (y >= 0) && (r >= 0) != (x >= 0) {
+			return 0, Overflow
+		}
+		return r, NotHalted
+	case opMul:
+		if x == 0 || y == 0 {
+			return 0, NotHalted
+		}
+		r := x * y
+		if r/y != x || (x == -1 && y == minInt) || (y == -1 && x == minInt) {
+			return 0, Overflow
+		}
+		return r, NotHalted
+	case opMod:
+		if y == 0 {
+			return 0, DivisionByZero
+		}
+		return x % y, NotHalted
+	default:
+		panic(fmt.Sprintf("not an arithmetic opcode: %v", code))
+	}
+}
+
+// arithmetic stores the result of an arithmetic opcode in register r
+func (c *Controller) arithmetic(code opcode, r register, v value) status {
+	if c.big {
+		x, y := c.getBig(r), v.fetchBig(c)
+		switch code {
+		case opAdd:
+			x.Add(x, y)
+		case opSub:
+			x.Sub(x, y)
+		case opMul:
+			x.Mul(x, y)
+		case opMod:
+			if y.Sign() == 0 {
+				return c.fault(code, DivisionByZero)
+			}
+			x.Rem(x, y)
+		}
+		c.bigRegisters[r] = x
+		return running
+	}
+
+	result, reason := calculate(code, c.get(r), v.fetch(c))
+	if reason != NotHalted {
+		return c.fault(code, reason)
+	}
+	c.set(r, result)
+	return running
+}
+
+// fetchInt resolves a value that has to fit in an int, like a jump offset or a value that is sent
+func (c *Controller) fetchInt(v value) (int, bool) {
+	if !c.big {
+		return v.fetch(c), true
+	}
+	b := v.fetchBig(c)
+	if !b.IsInt64() || b.Int64() > int64(maxInt) || b.Int64() < int64(minInt) {
+		return 0, false
+	}
+	return int(b.Int64()), true
+}
+
+// jumpBy jumps with an offset that has to fit in an int
+func (c *Controller) jumpBy(code opcode, offset value) status {
+	o, ok := c.fetchInt(offset)
+	if !ok {
+		return c.fault(code, Overflow)
+	}
+	c.jump(o)
+	return running
+}
+
+// sign returns -1, 0 or +1 depending on the sign of the value
+func (c *Controller) sign(v value) int {
+	if c.big {
+		return v.fetchBig(c).Sign()
+	}
+	switch x := v.fetch(c); {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// fault halts the controller on the current instruction because of an arithmetic error
+func (c *Controller) fault(code opcode, reason HaltReason) status {
+	c.Err = ArithmeticError{c.irPointer, opcodeNames[code], reason}
+	return c.halt(reason)
+}
+
+func (c *Controller) getBig(r register) *big.Int {
+	if !c.big {
+		return big.NewInt(int64(c.get(r)))
+	}
+	if v, ok := c.bigRegisters[r]; ok {
+		return new(big.Int).Set(v)
+	}
+	return new(big.Int)
+}
+
+// BigRegister returns the value of the named register, in either mode.
+func (c *Controller) BigRegister(name string) *big.Int {
+	r, err := asReg(name)
+	if err != nil {
+		return new(big.Int)
+	}
+	return c.getBig(r)
+}
+
+func (c *Controller) BigRegisters() map[string]*big.Int {
+	registers := make(map[string]*big.Int)
+	if !c.big {
+		for r, v := range c.registers {
+			registers[string(r)] = big.NewInt(int64(v))
+		}
+		return registers
+	}
+	for r, v := range c.bigRegisters {
+		registers[string(r)] = new(big.Int).Set(v)
+	}
+	return registers
+}
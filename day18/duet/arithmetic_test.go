@@ -0,0 +1,128 @@
+package duet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// doubles the register counter for the given amount of times
+const powerOfTwo = `set counter %v
+set value 1
+mul value 2
+add counter -1
+jgz counter -2`
+
+func parse(t *testing.T, input string) []Instruction {
+	instructions, err := ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instructions
+}
+
+func Test_calculate(t *testing.T) {
+	tests := []struct {
+		code     opcode
+		x, y     int
+		expected int
+		reason   HaltReason
+	}{
+		{opAdd, maxInt - 1, 1, maxInt, NotHalted},
+		{opAdd, maxInt, 1, 0, Overflow},
+		{opAdd, minInt, -1, 0, Overflow},
+		{opSub, minInt + 1, 1, minInt, NotHalted},
+		{opSub, minInt, 1, 0, Overflow},
+		{opSub, 0, minInt, 0, Overflow},
+		{opMul, maxInt/2 + 1, 2, 0, Overflow},
+		{opMul, minInt, -1, 0, Overflow},
+		{opMul, -1, minInt, 0, Overflow},
+		{opMul, -3, 7, -21, NotHalted},
+		{opMod, -7, 3, -1, NotHalted},
+		{opMod, 7, 0, 0, DivisionByZero},
+	}
+	for _, tt := range tests {
+		got, reason := calculate(tt.code, tt.x, tt.y)
+		if got != tt.expected || reason != tt.reason {
+			t.Errorf("calculate(%v, %v, %v) = %v, %v, but expected %v, %v", opcodeNames[tt.code], tt.x, tt.y, got, reason, tt.expected, tt.reason)
+		}
+	}
+}
+
+func TestController_Overflow(t *testing.T) {
+	instructions := parse(t, fmt.Sprintf(powerOfTwo, 100))
+
+	c := NewControllerV3()
+	c.Run(instructions)
+
+	if c.HaltReason != Overflow {
+		t.Fatalf("expected overflow, but halted with %v", c.HaltReason)
+	}
+	expected := ArithmeticError{2, "mul", Overflow}
+	if c.Err != expected {
+		t.Errorf("Err = %v, but expected %v", c.Err, expected)
+	}
+
+	program, err := Compile(instructions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiled := NewControllerV3()
+	program.Run(&compiled)
+
+	if compiled.Err != expected || compiled.Register("value") != c.Register("value") {
+		t.Errorf("compiled program halted with %v and value %v, but expected %v and %v", compiled.Err, compiled.Register("value"), expected, c.Register("value"))
+	}
+}
+
+func TestController_DivisionByZero(t *testing.T) {
+	c := NewControllerV3()
+	c.Run(parse(t, `set a 5
+mod a b`))
+
+	expected := ArithmeticError{1, "mod", DivisionByZero}
+	if c.Err != expected {
+		t.Errorf("Err = %v, but expected %v", c.Err, expected)
+	}
+}
+
+func TestController_BigInt(t *testing.T) {
+	c := NewControllerV3(WithBigInt())
+	c.Run(parse(t, fmt.Sprintf(powerOfTwo, 100)))
+
+	expected := new(big.Int).Lsh(big.NewInt(1), 100)
+
+	if c.HaltReason != OutOfBounds || c.BigRegister("value").Cmp(expected) != 0 {
+		t.Errorf("halted with %v and value %v, but expected %v and %v", c.HaltReason, c.BigRegister("value"), OutOfBounds, expected)
+	}
+	if _, ok := c.Registers()["value"]; ok {
+		t.Errorf("expected a value that does not fit in an int to be left out of Registers()")
+	}
+
+	data, err := json.Marshal(TakeSnapshot(&c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := s.Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored[0].BigRegister("value").Cmp(expected) != 0 {
+		t.Errorf("restored value = %v, but expected %v", restored[0].BigRegister("value"), expected)
+	}
+}
+
+func TestController_BigIntJumpOverflow(t *testing.T) {
+	c := NewControllerV3(WithBigInt())
+	c.Run(parse(t, fmt.Sprintf(powerOfTwo, 70)+"\njgz 1 value"))
+
+	expected := ArithmeticError{5, "jgz", Overflow}
+	if c.Err != expected {
+		t.Errorf("Err = %v, but expected %v", c.Err, expected)
+	}
+}
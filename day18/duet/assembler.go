@@ -0,0 +1,140 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode"
+)
+
+// ParseError points at the field of an instruction the assembler rejected, Line and Column are 1-based.
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %v, column %v: %v", e.Line, e.Column, e.Err)
+}
+
+// ParseErrors holds the rejected instructions of a program in program order, with at most one error per line.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseInstructions parses a program with an instruction per line. Blank lines and everything after a # are
+// ignored. If any line can not be parsed, the error is a ParseErrors listing all of them.
+func ParseInstructions(in string) (instructions []Instruction, err error) {
+	var parseErrors ParseErrors
+
+	for i, line := range strings.Split(in, "\n") {
+		if comment := strings.IndexRune(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+
+		fields := splitFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ir, err := parseInstruction(fields)
+		if err != nil {
+			parseErrors = append(parseErrors, newParseError(i+1, fields, err))
+			continue
+		}
+		instructions = append(instructions, ir)
+	}
+
+	if len(parseErrors) > 0 {
+		return instructions, parseErrors
+	}
+	return instructions, nil
+}
+
+func newParseError(line int, fields []field, err error) ParseError {
+	if fe, ok := err.(fieldError); ok {
+		return ParseError{line, fe.column, fe.err}
+	}
+
+	// a missing argument, point right after the last field
+	last := fields[len(fields)-1]
+	return ParseError{line, last.column + len([]rune(last.text)) + 1, err}
+}
+
+func splitFields(line string) (fields []field) {
+	start := -1
+	column := 0
+
+	runes := []rune(line)
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				fields = append(fields, field{string(runes[start:i]), column})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			column = i + 1
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, field{string(runes[start:]), column})
+	}
+	return
+}
+
+func parseInstruction(fields []field) (ir Instruction, err error) {
+	opcode := fields[0]
+	args := fields[1:]
+
+	switch opcode.text {
+	case "snd":
+		return newSnd(args)
+
+	case "set":
+		return newSet(args)
+
+	case "add":
+		return newAdd(args)
+
+	case "sub":
+		return newSub(args)
+
+	case "mul":
+		return newMul(args)
+
+	case "mod":
+		return newMod(args)
+
+	case "rcv":
+		return newRcv(args)
+
+	case "jgz":
+		return newJgz(args)
+
+	case "jnz":
+		return newJnz(args)
+
+	default:
+		err = errorAt(opcode, errors.New(fmt.Sprintf("could not recognize instruction %q", opcode.text)))
+		return
+	}
+}
+
+// Disassemble writes instructions back in the format ParseInstructions reads.
+func Disassemble(instructions []Instruction) string {
+	lines := make([]string, len(instructions))
+	for i, ir := range instructions {
+		lines[i] = ir.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package duet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInstructions_BlankLinesAndComments(t *testing.T) {
+	input := `# count down from 3
+
+set a 3   # counter
+add a -1
+jgz a -1
+`
+	instructions, err := ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "set a 3\nadd a -1\njgz a -1"
+	if got := Disassemble(instructions); got != expected {
+		t.Errorf("Disassemble(...) = %q, but expected %q", got, expected)
+	}
+}
+
+func TestParseInstructions_Errors(t *testing.T) {
+	input := `set a 1
+jmp a 2
+add a
+mul 2b 2
+snd 1 2
+
+rcv a
+jgz a x-2`
+
+	instructions, err := ParseInstructions(input)
+
+	parseErrors, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("expected ParseErrors, but got %v", err)
+	}
+
+	expected := []struct {
+		line, column int
+	}{
+		{2, 1},
+		{3, 7},
+		{4, 5},
+		{5, 7},
+		{8, 7},
+	}
+	if len(parseErrors) != len(expected) {
+		t.Fatalf("expected %v errors, but got\n%v", len(expected), parseErrors)
+	}
+	for i, e := range expected {
+		if parseErrors[i].Line != e.line || parseErrors[i].Column != e.column {
+			t.Errorf("error %v at line %v, column %v, but expected line %v, column %v: %v", i, parseErrors[i].Line, parseErrors[i].Column, e.line, e.column, parseErrors[i])
+		}
+	}
+
+	if len(instructions) != 2 {
+		t.Errorf("expected the 2 valid lines to be parsed, but got %v", instructions)
+	}
+}
+
+func TestDisassemble_RoundTrip(t *testing.T) {
+	input := `set i 31
+set a 1
+mul p 17
+jgz p p
+sub a -2
+jnz 1 -3
+mod b 10000
+snd b
+rcv a`
+
+	instructions, err := ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	printed := Disassemble(instructions)
+	if printed != input {
+		t.Errorf("Disassemble(...) = %q, but expected %q", printed, input)
+	}
+
+	reparsed, err := ParseInstructions(printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reparsed, instructions) {
+		t.Errorf("parse -> print -> parse gave %v, but expected %v", reparsed, instructions)
+	}
+}
@@ -0,0 +1,207 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+type opcode uint8
+
+const (
+	opSet opcode = iota
+	opAdd
+	opSub
+	opMul
+	opMod
+	opJgz
+	opJnz
+	opSnd
+	opRcv
+	numOpcodes
+)
+
+var opcodeNames = [numOpcodes]string{"set", "add", "sub", "mul", "mod", "jgz", "jnz", "snd", "rcv"}
+
+// operand is either a constant or, if isReg is set, an index in the register array
+type operand struct {
+	isReg bool
+	n     int
+}
+
+type op struct {
+	code opcode
+	a, b operand
+}
+
+// Program is a list of instructions compiled to operate on a fixed array of registers instead of a map.
+type Program struct {
+	ops          []op
+	registers    []register
+	instructions []Instruction
+}
+
+func Compile(instructions []Instruction) (p Program, err error) {
+	index := make(map[register]int)
+
+	reg := func(r register) operand {
+		i, ok := index[r]
+		if !ok {
+			i = len(p.registers)
+			index[r] = i
+			p.registers = append(p.registers, r)
+		}
+		return operand{true, i}
+	}
+	val := func(v value) operand {
+		switch v := v.(type) {
+		case valueReg:
+			return reg(register(v))
+		case valueInt:
+			return operand{false, int(v)}
+		default:
+			panic(fmt.Sprintf("unknown value %T", v))
+		}
+	}
+
+	p.ops = make([]op, len(instructions))
+	p.instructions = instructions
+
+	for i, ir := range instructions {
+		switch ir := ir.(type) {
+		case set:
+			p.ops[i] = op{opSet, reg(ir.reg), val(ir.val)}
+		case add:
+			p.ops[i] = op{opAdd, reg(ir.reg), val(ir.val)}
+		case sub:
+			p.ops[i] = op{opSub, reg(ir.reg), val(ir.val)}
+		case mul:
+			p.ops[i] = op{opMul, reg(ir.reg), val(ir.val)}
+		case mod:
+			p.ops[i] = op{opMod, reg(ir.reg), val(ir.val)}
+		case jgz:
+			p.ops[i] = op{opJgz, val(ir.value), val(ir.offset)}
+		case jnz:
+			p.ops[i] = op{opJnz, val(ir.value), val(ir.offset)}
+		case snd:
+			p.ops[i] = op{code: opSnd, a: val(ir.val)}
+		case rcv:
+			p.ops[i] = op{code: opRcv, a: reg(ir.reg)}
+		default:
+			return p, errors.New(fmt.Sprintf("could not compile instruction %v (%T)", i, ir))
+		}
+	}
+	return
+}
+
+// Run executes the program on the state of the controller until it halts, with the same results as
+// Controller.Run. Tracers are only notified of sends, receives and halting, not of every step. Controllers in big
+// int mode run the original instructions instead.
+func (p *Program) Run(c *Controller) HaltReason {
+	if c.big {
+		return c.Run(p.instructions)
+	}
+	if c.HaltReason == NotHalted && p.run(c) == blocked {
+		c.halt(Deadlock)
+	}
+	return c.HaltReason
+}
+
+func (p *Program) run(c *Controller) (s status) {
+	regs := make([]int, len(p.registers))
+	written := make([]bool, len(p.registers))
+	for i, r := range p.registers {
+		regs[i], written[i] = c.registers[r]
+	}
+
+	var counts [numOpcodes]int
+	ops := p.ops
+	ip := c.irPointer
+
+	fetch := func(o operand) int {
+		if o.isReg {
+			return regs[o.n]
+		}
+		return o.n
+	}
+
+	s = running
+	for s == running {
+		if ip < 0 || ip >= len(ops) {
+			s = c.halt(OutOfBounds)
+			break
+		}
+
+		o := &ops[ip]
+
+		switch o.code {
+		case opSet:
+			regs[o.a.n] = fetch(o.b)
+			written[o.a.n] = true
+		case opAdd, opSub, opMul, opMod:
+			result, reason := calculate(o.code, regs[o.a.n], fetch(o.b))
+			if reason != NotHalted {
+				c.irPointer = ip
+				s = c.fault(o.code, reason)
+				break
+			}
+			regs[o.a.n] = result
+			written[o.a.n] = true
+		case opJgz:
+			if fetch(o.a) > 0 {
+				ip += fetch(o.b) - 1
+			}
+		case opJnz:
+			if fetch(o.a) != 0 {
+				ip += fetch(o.b) - 1
+			}
+		case opSnd:
+			switch c.version {
+			case v1:
+				c.playSound(fetch(o.a))
+			case v2:
+				s = c.send(fetch(o.a))
+			default:
+				c.irPointer = ip
+				s = c.unsupported(o.code)
+			}
+		case opRcv:
+			switch c.version {
+			case v1:
+				if regs[o.a.n] != 0 {
+					s = c.halt(Recovered)
+				}
+			case v2:
+				val, ok := c.recv()
+				if !ok {
+					s = blocked
+					break
+				}
+				regs[o.a.n] = val
+				written[o.a.n] = true
+			default:
+				c.irPointer = ip
+				s = c.unsupported(o.code)
+			}
+		}
+
+		if s != blocked {
+			counts[o.code] += 1
+		}
+		if s == running {
+			ip += 1
+		}
+	}
+
+	c.irPointer = ip
+	for i, r := range p.registers {
+		if written[i] {
+			c.registers[r] = regs[i]
+		}
+	}
+	for code, n := range counts {
+		if n > 0 {
+			c.OpcodeCounts[opcodeNames[code]] += n
+		}
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package duet
+
+import (
+	"reflect"
+	"testing"
+)
+
+var compileTests = []struct {
+	name          string
+	input         string
+	newController func() Controller
+}{
+	{"v1", `set a 1
+add a 2
+mul a a
+mod a 5
+snd a
+set a 0
+rcv a
+jgz a -1
+set a 1
+jgz a -2`, func() Controller { return NewControllerV1() }},
+	{"v2", `snd p
+snd 7
+rcv a
+rcv b
+rcv c`, func() Controller {
+		// a program sending to itself
+		m := NewMailbox()
+		return NewControllerV2(m, m, 3)
+	}},
+	{"v3", `set b 17
+set c b
+sub c -34
+set d 2
+set g b
+mod g d
+jnz g 2
+sub h -1
+sub b -17
+set g b
+sub g c
+jnz g -8
+set z 0`, func() Controller { return NewControllerV3() }},
+}
+
+func TestProgram_Run(t *testing.T) {
+	for _, tt := range compileTests {
+		t.Run(tt.name, func(t *testing.T) {
+			instructions, err := ParseInstructions(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			program, err := Compile(instructions)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := tt.newController()
+			got := tt.newController()
+
+			expected.Run(instructions)
+			program.Run(&got)
+
+			if !reflect.DeepEqual(got.Registers(), expected.Registers()) {
+				t.Errorf("registers = %v, but expected %v", got.Registers(), expected.Registers())
+			}
+			if !reflect.DeepEqual(got.OpcodeCounts, expected.OpcodeCounts) {
+				t.Errorf("opcode counts = %v, but expected %v", got.OpcodeCounts, expected.OpcodeCounts)
+			}
+			if got.HaltReason != expected.HaltReason || got.irPointer != expected.irPointer {
+				t.Errorf("halted with %v at %v, but expected %v at %v", got.HaltReason, got.irPointer, expected.HaltReason, expected.irPointer)
+			}
+			if got.LastFrequency != expected.LastFrequency || got.ValuesSent != expected.ValuesSent {
+				t.Errorf("last frequency = %v and values sent = %v, but expected %v and %v", got.LastFrequency, got.ValuesSent, expected.LastFrequency, expected.ValuesSent)
+			}
+		})
+	}
+}
@@ -2,83 +2,202 @@ package duet
 
 import (
 	"fmt"
-	"time"
+	"math/big"
 )
 
-type register rune
+type register string
 
 type controllerVersion uint8
 
 const (
 	v1 controllerVersion = iota
 	v2
+	v3
+)
+
+// status is the state a controller is in after executing an instruction
+type status uint8
+
+const (
+	running status = iota
+	blocked
+	halted
 )
 
 type Controller struct {
-	id        int
-	registers map[register]int
-	irPointer int
-	version   controllerVersion
+	id         int
+	registers  map[register]int
+	irPointer  int
+	version    controllerVersion
+	HaltReason HaltReason
+	// set when the controller halted on an arithmetic error or an unsupported instruction
+	Err    error
+	tracer Tracer
+	// big int mode
+	big          bool
+	bigRegisters map[register]*big.Int
+	// number of times each opcode has been executed
+	OpcodeCounts map[string]int
 	// v1
 	LastFrequency int
 	// v2
-	sendChan   chan<- int
-	recvChan   <-chan int
-	doneChan   chan<- bool
+	outboxes   []*Mailbox
+	inbox      *Mailbox
 	ValuesSent int
 }
 
-func NewControllerV1() (c Controller) {
+type Option func(c *Controller)
+
+// WithRegister sets the initial value of a register. It returns an error if name is not a valid register.
+func WithRegister(name string, value int) (Option, error) {
+	r, err := asReg(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *Controller) {
+		c.set(r, value)
+	}, nil
+}
+
+func NewControllerV1(options ...Option) (c Controller) {
 	c.registers = make(map[register]int)
 	c.version = v1
+	c.apply(options)
 	return
 }
 
-func NewControllerV2(sendChan chan<- int, recvChan <-chan int, programId int) (c Controller, done <-chan bool) {
+func NewControllerV2(outbox, inbox *Mailbox, programId int, options ...Option) (c Controller) {
+	return newControllerV2([]*Mailbox{outbox}, inbox, programId, options)
+}
+
+// newControllerV2 creates a controller that sends every value to all of its outboxes.
+func newControllerV2(outboxes []*Mailbox, inbox *Mailbox, programId int, options []Option) (c Controller) {
 	c.id = programId
 	c.registers = make(map[register]int)
 	c.version = v2
 
-	c.set('p', programId)
+	c.set("p", programId)
 
-	c.sendChan = sendChan
-	c.recvChan = recvChan
+	c.outboxes = outboxes
+	c.inbox = inbox
 
-	doneChan := make(chan bool)
-	c.doneChan = doneChan
-
-	return c, doneChan
+	c.apply(options)
+	return
 }
 
-func (c *Controller) Run(instructions []Instruction) {
-	for {
-		ir := instructions[c.irPointer]
+// NewControllerV3 creates a controller for the coprocessor, which does not support snd and rcv.
+func NewControllerV3(options ...Option) (c Controller) {
+	c.registers = make(map[register]int)
+	c.version = v3
+	c.apply(options)
+	return
+}
 
-		fmt.Printf("%v | %v | %T %v\n", c.id, c.irPointer, ir, ir)
+func (c *Controller) apply(options []Option) {
+	c.OpcodeCounts = make(map[string]int)
+	c.tracer = NopTracer{}
+	for _, option := range options {
+		option(c)
+	}
+}
 
-		shouldHalt := ir.operateOn(c)
-		if shouldHalt {
-			break
+// Run executes instructions until the controller halts. A controller running on its own can never be unblocked,
+// so waiting on rcv or on a full mailbox is reported as a deadlock.
+func (c *Controller) Run(instructions []Instruction) HaltReason {
+	for c.HaltReason == NotHalted {
+		if c.step(instructions) == blocked {
+			c.halt(Deadlock)
 		}
+	}
+	return c.HaltReason
+}
 
+// step executes the instruction at the instruction pointer. A blocked instruction does not advance the instruction
+// pointer, so it is retried on the next step.
+func (c *Controller) step(instructions []Instruction) status {
+	if c.HaltReason != NotHalted {
+		return halted
+	}
+	if c.irPointer < 0 || c.irPointer >= len(instructions) {
+		return c.halt(OutOfBounds)
+	}
+
+	ir := instructions[c.irPointer]
+
+	s := ir.operateOn(c)
+	if s != blocked {
+		c.OpcodeCounts[ir.Opcode()] += 1
+		c.tracer.OnStep(c.id, c.irPointer, ir)
+	}
+	if s == running {
 		c.irPointer += 1
-		if c.irPointer < 0 || c.irPointer >= len(instructions) {
-			break
-		}
 	}
+	return s
+}
+
+func (c *Controller) halt(reason HaltReason) status {
+	c.HaltReason = reason
+	c.tracer.OnHalt(c.id, reason)
+	return halted
+}
+
+// UnsupportedError describes an instruction the version of a controller can not execute, like snd on a coprocessor.
+type UnsupportedError struct {
+	Index  int
+	Opcode string
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("instruction %v (%v): not supported by this controller", e.Index, e.Opcode)
+}
+
+// unsupported halts the controller on the current instruction because its version can not execute it
+func (c *Controller) unsupported(code opcode) status {
+	c.Err = UnsupportedError{c.irPointer, opcodeNames[code]}
+	return c.halt(Unsupported)
+}
+
+func (c *Controller) IrPointer() int {
+	return c.irPointer
+}
+
+// Register returns the value of the named register, unknown registers are 0. In big int mode, the value is
+// truncated to an int, use BigRegister instead.
+func (c *Controller) Register(name string) int {
+	r, err := asReg(name)
+	if err != nil {
+		return 0
+	}
+	return c.get(r)
+}
 
-	if c.doneChan != nil {
-		c.doneChan <- true
-		close(c.doneChan)
+// Registers returns the value of all registers. In big int mode, registers that do not fit in an int are left out,
+// use BigRegisters instead.
+func (c *Controller) Registers() map[string]int {
+	registers := make(map[string]int, len(c.registers))
+	for r, v := range c.registers {
+		registers[string(r)] = v
+	}
+	for r, v := range c.bigRegisters {
+		if v.IsInt64() && v.Int64() <= int64(maxInt) && v.Int64() >= int64(minInt) {
+			registers[string(r)] = int(v.Int64())
+		}
 	}
-	fmt.Printf("%v | done\n", c.id)
+	return registers
 }
 
 func (c *Controller) get(r register) int {
+	if c.big {
+		return int(c.getBig(r).Int64())
+	}
 	return c.registers[r]
 }
 
 func (c *Controller) set(r register, value int) {
+	if c.big {
+		c.bigRegisters[r] = big.NewInt(int64(value))
+		return
+	}
 	c.registers[r] = value
 }
 
@@ -94,35 +213,37 @@ func (c *Controller) playSound(frequency int) {
 	c.LastFrequency = frequency
 }
 
-func (c *Controller) send(value int) (success bool) {
+func (c *Controller) send(value int) status {
 	if c.version != v2 {
 		panic("instruction not supported")
 	}
 
-	select {
-	case c.sendChan <- value:
-		c.ValuesSent += 1
-		success = true
-
-	default:
-		fmt.Printf("%v | can't send any more values\n", c.id)
-		success = false
+	for _, m := range c.outboxes {
+		if m.isFull() {
+			m.Overflows += 1
+			if m.policy == OverflowBlock {
+				return blocked
+			}
+			return c.halt(SendFailed)
+		}
 	}
-	return
+	for _, m := range c.outboxes {
+		m.push(value)
+	}
+
+	c.ValuesSent += 1
+	c.tracer.OnSend(c.id, value)
+	return running
 }
 
-func (c *Controller) recv() (value int, timedOut bool) {
+func (c *Controller) recv() (value int, ok bool) {
 	if c.version != v2 {
 		panic("instruction not supported")
 	}
 
-	select {
-	case value = <-c.recvChan:
-		timedOut = false
-
-	case <-time.After(1 * time.Second):
-		fmt.Printf("%v | timed out\n", c.id)
-		timedOut = true
+	value, ok = c.inbox.pop()
+	if ok {
+		c.tracer.OnRecv(c.id, value)
 	}
 	return
 }
@@ -0,0 +1,45 @@
+package duet
+
+import "testing"
+
+func TestController_Unsupported(t *testing.T) {
+	for _, input := range []string{"set a 1\nsnd a", "set a 1\nrcv a"} {
+		instructions := parse(t, input)
+		expected := UnsupportedError{1, instructions[1].Opcode()}
+
+		c := NewControllerV3()
+		c.Run(instructions)
+
+		if c.HaltReason != Unsupported || c.Err != expected {
+			t.Errorf("%q: halted with %v and %v, but expected %v", input, c.HaltReason, c.Err, expected)
+		}
+
+		program, err := Compile(instructions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compiled := NewControllerV3()
+		program.Run(&compiled)
+
+		if compiled.HaltReason != Unsupported || compiled.Err != expected {
+			t.Errorf("%q: compiled program halted with %v and %v, but expected %v", input, compiled.HaltReason, compiled.Err, expected)
+		}
+	}
+}
+
+func TestWithRegister(t *testing.T) {
+	option, err := WithRegister("a", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewControllerV3(option)
+	if c.Register("a") != 5 {
+		t.Errorf("expected a = 5, but got %v", c.Register("a"))
+	}
+
+	for _, name := range []string{"", "1a", "a b"} {
+		if _, err := WithRegister(name, 5); err == nil {
+			t.Errorf("expected register %q to be rejected", name)
+		}
+	}
+}
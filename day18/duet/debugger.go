@@ -0,0 +1,194 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+type WatchKind uint8
+
+const (
+	// WatchChange triggers whenever the register gets a different value.
+	WatchChange WatchKind = iota
+	// WatchValue triggers when the register reaches Value.
+	WatchValue
+)
+
+type Watchpoint struct {
+	Register string
+	Kind     WatchKind
+	Value    int
+}
+
+func (w Watchpoint) String() string {
+	if w.Kind == WatchValue {
+		return fmt.Sprintf("%v == %v", w.Register, w.Value)
+	}
+	return fmt.Sprintf("%v changes", w.Register)
+}
+
+type StopReason uint8
+
+const (
+	Stepped StopReason = iota
+	AtBreakpoint
+	AtWatchpoint
+	Blocked
+	Halted
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case Stepped:
+		return "stepped"
+	case AtBreakpoint:
+		return "breakpoint"
+	case AtWatchpoint:
+		return "watchpoint"
+	case Blocked:
+		return "blocked"
+	case Halted:
+		return "halted"
+	default:
+		return "unknown"
+	}
+}
+
+// Stop tells why the debugger returned control. Watchpoint is only set when Reason is AtWatchpoint, OldValue and
+// NewValue are the values of the watched register before and after the last step.
+type Stop struct {
+	Reason     StopReason
+	IrPointer  int
+	Watchpoint Watchpoint
+	OldValue   int
+	NewValue   int
+}
+
+// Debugger executes the instructions of a single controller under control of the user.
+type Debugger struct {
+	c            *Controller
+	instructions []Instruction
+	breakpoints  map[int]bool
+	watchpoints  []Watchpoint
+	// the instruction pointer of the last stop, -1 before the first one
+	lastStop int
+}
+
+func NewDebugger(c *Controller, instructions []Instruction) Debugger {
+	return Debugger{
+		c:            c,
+		instructions: instructions,
+		breakpoints:  make(map[int]bool),
+		lastStop:     -1,
+	}
+}
+
+func (d *Debugger) Controller() *Controller {
+	return d.c
+}
+
+func (d *Debugger) Instructions() []Instruction {
+	return d.instructions
+}
+
+func (d *Debugger) AddBreakpoint(irPointer int) error {
+	if irPointer < 0 || irPointer >= len(d.instructions) {
+		return errors.New(fmt.Sprintf("breakpoint %v is outside of the program", irPointer))
+	}
+	d.breakpoints[irPointer] = true
+	return nil
+}
+
+func (d *Debugger) RemoveBreakpoint(irPointer int) {
+	delete(d.breakpoints, irPointer)
+}
+
+func (d *Debugger) Breakpoints() (irPointers []int) {
+	for i := range d.instructions {
+		if d.breakpoints[i] {
+			irPointers = append(irPointers, i)
+		}
+	}
+	return
+}
+
+func (d *Debugger) AddWatchpoint(w Watchpoint) error {
+	if _, err := asReg(w.Register); err != nil {
+		return err
+	}
+	d.watchpoints = append(d.watchpoints, w)
+	return nil
+}
+
+func (d *Debugger) RemoveWatchpoint(register string) {
+	watchpoints := d.watchpoints[:0]
+	for _, w := range d.watchpoints {
+		if w.Register != register {
+			watchpoints = append(watchpoints, w)
+		}
+	}
+	d.watchpoints = watchpoints
+}
+
+func (d *Debugger) Watchpoints() []Watchpoint {
+	return d.watchpoints
+}
+
+// Step executes a single instruction.
+func (d *Debugger) Step() Stop {
+	stop := d.step()
+	d.lastStop = stop.IrPointer
+	return stop
+}
+
+func (d *Debugger) step() Stop {
+	before := make([]int, len(d.watchpoints))
+	for i, w := range d.watchpoints {
+		before[i] = d.c.Register(w.Register)
+	}
+
+	switch d.c.step(d.instructions) {
+	case blocked:
+		return Stop{Reason: Blocked, IrPointer: d.c.irPointer}
+	case halted:
+		return Stop{Reason: Halted, IrPointer: d.c.irPointer}
+	}
+
+	for i, w := range d.watchpoints {
+		after := d.c.Register(w.Register)
+		if w.triggers(before[i], after) {
+			return Stop{Reason: AtWatchpoint, IrPointer: d.c.irPointer, Watchpoint: w, OldValue: before[i], NewValue: after}
+		}
+	}
+
+	if d.breakpoints[d.c.irPointer] {
+		return Stop{Reason: AtBreakpoint, IrPointer: d.c.irPointer}
+	}
+	return Stop{Reason: Stepped, IrPointer: d.c.irPointer}
+}
+
+// Continue executes instructions until a breakpoint or watchpoint is hit, or the controller can not continue. A
+// breakpoint on the current instruction is hit before executing it, unless the debugger already stopped there.
+func (d *Debugger) Continue() Stop {
+	if ip := d.c.irPointer; d.breakpoints[ip] && d.lastStop != ip {
+		d.lastStop = ip
+		return Stop{Reason: AtBreakpoint, IrPointer: ip}
+	}
+	for {
+		stop := d.Step()
+		if stop.Reason != Stepped {
+			return stop
+		}
+	}
+}
+
+func (w Watchpoint) triggers(before, after int) bool {
+	switch w.Kind {
+	case WatchChange:
+		return before != after
+	case WatchValue:
+		return before != w.Value && after == w.Value
+	default:
+		return false
+	}
+}
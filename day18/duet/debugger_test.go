@@ -0,0 +1,102 @@
+package duet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestDebugger(t *testing.T) Debugger {
+	instructions, err := ParseInstructions(`set a 3
+add b 2
+add a -1
+jgz a -2
+snd b
+rcv b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewControllerV1()
+	return NewDebugger(&c, instructions)
+}
+
+func TestDebugger_Step(t *testing.T) {
+	d := newTestDebugger(t)
+
+	for _, expected := range []int{1, 2, 3, 1} {
+		stop := d.Step()
+		if stop.Reason != Stepped || stop.IrPointer != expected {
+			t.Errorf("Step() = %v at %v, but expected to step to %v", stop.Reason, stop.IrPointer, expected)
+		}
+	}
+
+	expected := map[string]int{"a": 2, "b": 2}
+	if !reflect.DeepEqual(d.Controller().Registers(), expected) {
+		t.Errorf("Registers() = %v, but expected %v", d.Controller().Registers(), expected)
+	}
+}
+
+func TestDebugger_Breakpoint(t *testing.T) {
+	d := newTestDebugger(t)
+
+	if err := d.AddBreakpoint(4); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddBreakpoint(6); err == nil {
+		t.Errorf("expected breakpoint outside of the program to be rejected")
+	}
+
+	stop := d.Continue()
+	if stop.Reason != AtBreakpoint || stop.IrPointer != 4 {
+		t.Errorf("Continue() = %v at %v, but expected breakpoint at 4", stop.Reason, stop.IrPointer)
+	}
+	if d.Controller().Register("b") != 6 {
+		t.Errorf("expected b = 6 at breakpoint, but got %v", d.Controller().Register("b"))
+	}
+
+	d.RemoveBreakpoint(4)
+
+	stop = d.Continue()
+	if stop.Reason != Halted || d.Controller().HaltReason != Recovered {
+		t.Errorf("Continue() = %v, but expected program to halt on rcv", stop.Reason)
+	}
+}
+
+func TestDebugger_BreakpointOnCurrentInstruction(t *testing.T) {
+	d := newTestDebugger(t)
+
+	d.AddBreakpoint(0)
+	d.AddBreakpoint(2)
+
+	// the loop runs instruction 2 three times
+	for _, expected := range []int{0, 2, 2, 2} {
+		stop := d.Continue()
+		if stop.Reason != AtBreakpoint || stop.IrPointer != expected {
+			t.Fatalf("Continue() = %v at %v, but expected breakpoint at %v", stop.Reason, stop.IrPointer, expected)
+		}
+	}
+
+	stop := d.Continue()
+	if stop.Reason != Halted || d.Controller().HaltReason != Recovered {
+		t.Errorf("Continue() = %v, but expected program to halt on rcv", stop.Reason)
+	}
+}
+
+func TestDebugger_Watchpoint(t *testing.T) {
+	d := newTestDebugger(t)
+
+	d.AddWatchpoint(Watchpoint{Register: "b", Kind: WatchValue, Value: 4})
+
+	stop := d.Continue()
+	if stop.Reason != AtWatchpoint || stop.OldValue != 2 || stop.NewValue != 4 {
+		t.Errorf("Continue() = %+v, but expected watchpoint b: 2 -> 4", stop)
+	}
+
+	d.RemoveWatchpoint("b")
+	d.AddWatchpoint(Watchpoint{Register: "a", Kind: WatchChange})
+
+	stop = d.Continue()
+	if stop.Reason != AtWatchpoint || stop.OldValue != 2 || stop.NewValue != 1 {
+		t.Errorf("Continue() = %+v, but expected watchpoint a: 2 -> 1", stop)
+	}
+}
@@ -0,0 +1,266 @@
+package decompile
+
+import (
+	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"strings"
+)
+
+// Decompile turns a program into pseudo-Go. A jump backwards to the start of a range becomes a for loop, a forward
+// jump over a range becomes an if, optionally with an else. Jumps to the start or right after the innermost loop
+// become continue and break, jumps outside of the program become return. Everything else is a goto to a label.
+func Decompile(instructions []duet.Instruction) string {
+	d := decompiler{
+		instructions: instructions,
+		labels:       make(map[int]bool),
+	}
+
+	d.emit(0, -1, "func program() {")
+	d.block(0, len(instructions), nil, 1)
+	d.emit(0, -1, "}")
+
+	return d.String()
+}
+
+type line struct {
+	depth int
+	// the instruction this line starts, -1 if none
+	index int
+	text  string
+}
+
+type loop struct {
+	head, exit int
+}
+
+type decompiler struct {
+	instructions []duet.Instruction
+	lines        []line
+	labels       map[int]bool
+}
+
+func (d *decompiler) emit(depth, index int, format string, args ...interface{}) {
+	d.lines = append(d.lines, line{depth, index, fmt.Sprintf(format, args...)})
+}
+
+func (d *decompiler) String() string {
+	var b strings.Builder
+	labelled := make(map[int]bool)
+
+	for _, l := range d.lines {
+		if l.index >= 0 && d.labels[l.index] && !labelled[l.index] {
+			labelled[l.index] = true
+			fmt.Fprintf(&b, "%vL%v:\n", strings.Repeat("\t", l.depth-1), l.index)
+		}
+		// empty lines only hold a place for a label
+		if l.text != "" {
+			fmt.Fprintf(&b, "%v%v\n", strings.Repeat("\t", l.depth), l.text)
+		}
+	}
+	return b.String()
+}
+
+// jump describes the condition of a jump and, if its offset is constant, its target
+type jump struct {
+	// the condition of the jump, empty if it is always taken
+	cond   string
+	never  bool
+	target int
+}
+
+func (d *decompiler) asJump(i int) (j jump, isJump, isConstant bool) {
+	ir := d.instructions[i]
+	operands := ir.Operands()
+
+	var op string
+	switch ir.Opcode() {
+	case "jgz":
+		op = ">"
+	case "jnz":
+		op = "!="
+	default:
+		return j, false, false
+	}
+
+	cond := operands[0]
+	if cond.IsRegister() {
+		j.cond = fmt.Sprintf("%v %v 0", cond.Register, op)
+	} else if (op == ">" && cond.Value <= 0) || (op == "!=" && cond.Value == 0) {
+		j.never = true
+	}
+
+	offset := operands[1]
+	if offset.IsRegister() {
+		return j, true, false
+	}
+	j.target = i + offset.Value
+	return j, true, true
+}
+
+// negate returns the opposite of a condition created by asJump
+func negate(cond string) string {
+	for _, r := range [][2]string{{" > ", " <= "}, {" != ", " == "}} {
+		if strings.Contains(cond, r[0]) {
+			return strings.Replace(cond, r[0], r[1], 1)
+		}
+	}
+	return "!(" + cond + ")"
+}
+
+// backJumpTo finds the last jump in [i, hi) with i as constant target
+func (d *decompiler) backJumpTo(i, hi int) (int, bool) {
+	for j := hi - 1; j >= i; j-- {
+		if jmp, isJump, isConstant := d.asJump(j); isJump && isConstant && !jmp.never && jmp.target == i {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// resolve follows jumps that are always taken from target, as they do not get a line of their own when they close
+// a loop
+func (d *decompiler) resolve(target int) int {
+	seen := make(map[int]bool)
+	for target >= 0 && target < len(d.instructions) && !seen[target] {
+		seen[target] = true
+
+		jmp, isJump, isConstant := d.asJump(target)
+		if !isJump || !isConstant || jmp.never || jmp.cond != "" {
+			break
+		}
+		target = jmp.target
+	}
+	return target
+}
+
+// conditional emits a statement guarded by the condition of a jump
+func (d *decompiler) conditional(depth, index int, cond, statement string) {
+	if cond == "" {
+		d.emit(depth, index, "%v", statement)
+		return
+	}
+	d.emit(depth, index, "if %v {", cond)
+	d.emit(depth+1, -1, "%v", statement)
+	d.emit(depth, -1, "}")
+}
+
+func (d *decompiler) block(lo, hi int, inner *loop, depth int) {
+	n := len(d.instructions)
+
+	for i := lo; i < hi; {
+		if inner == nil || inner.head != i {
+			if j, ok := d.backJumpTo(i, hi); ok {
+				jmp, _, _ := d.asJump(j)
+
+				d.emit(depth, i, "for {")
+				d.block(i, j, &loop{i, j + 1}, depth+1)
+				if jmp.cond != "" {
+					d.conditional(depth+1, j, negate(jmp.cond), "break")
+				} else {
+					d.emit(depth+1, j, "")
+				}
+				d.emit(depth, -1, "}")
+
+				i = j + 1
+				continue
+			}
+		}
+
+		jmp, isJump, isConstant := d.asJump(i)
+
+		switch {
+		case !isJump:
+			d.emit(depth, i, "%v", statement(d.instructions[i]))
+			i++
+
+		case jmp.never:
+			d.emit(depth, i, "// %v: never jumps", d.instructions[i])
+			i++
+
+		case !isConstant:
+			d.conditional(depth, i, jmp.cond, fmt.Sprintf("jump(%v) // dynamic", d.instructions[i].Operands()[1]))
+			i++
+
+		case inner != nil && jmp.target == inner.exit:
+			d.conditional(depth, i, jmp.cond, "break")
+			i++
+
+		case inner != nil && jmp.target == inner.head:
+			d.conditional(depth, i, jmp.cond, "continue")
+			i++
+
+		case jmp.target < 0 || jmp.target >= n:
+			d.conditional(depth, i, jmp.cond, "return")
+			i++
+
+		case jmp.cond != "" && jmp.target > i && jmp.target <= hi:
+			i = d.ifElse(i, hi, jmp, inner, depth)
+
+		default:
+			target := d.resolve(jmp.target)
+			if target < 0 || target >= n {
+				d.conditional(depth, i, jmp.cond, "return")
+			} else {
+				d.labels[target] = true
+				d.conditional(depth, i, jmp.cond, fmt.Sprintf("goto L%v", target))
+			}
+			i++
+		}
+	}
+}
+
+// ifElse emits the range skipped by a forward jump as if block, returns where to continue
+func (d *decompiler) ifElse(i, hi int, jmp jump, inner *loop, depth int) int {
+	t := jmp.target
+
+	// the then block ends with a jump over the else block
+	if t-1 > i {
+		if skip, isJump, isConstant := d.asJump(t - 1); isJump && isConstant && skip.cond == "" && !skip.never &&
+			skip.target > t && skip.target <= hi && (inner == nil || skip.target != inner.exit) {
+
+			// only a jump over the else block, so the else block is all there is
+			if t-1 == i+1 {
+				d.emit(depth, i, "if %v {", jmp.cond)
+				d.emit(depth+1, t-1, "")
+				d.block(t, skip.target, inner, depth+1)
+				d.emit(depth, -1, "}")
+				return skip.target
+			}
+
+			d.emit(depth, i, "if %v {", negate(jmp.cond))
+			d.block(i+1, t-1, inner, depth+1)
+			d.emit(depth, t-1, "} else {")
+			d.block(t, skip.target, inner, depth+1)
+			d.emit(depth, -1, "}")
+			return skip.target
+		}
+	}
+
+	d.emit(depth, i, "if %v {", negate(jmp.cond))
+	d.block(i+1, t, inner, depth+1)
+	d.emit(depth, -1, "}")
+	return t
+}
+
+func statement(ir duet.Instruction) string {
+	operands := ir.Operands()
+
+	switch ir.Opcode() {
+	case "set":
+		return fmt.Sprintf("%v = %v", operands[0], operands[1])
+	case "add":
+		return fmt.Sprintf("%v += %v", operands[0], operands[1])
+	case "sub":
+		return fmt.Sprintf("%v -= %v", operands[0], operands[1])
+	case "mul":
+		return fmt.Sprintf("%v *= %v", operands[0], operands[1])
+	case "mod":
+		return fmt.Sprintf("%v %%= %v", operands[0], operands[1])
+	default:
+		args := make([]string, len(operands))
+		for i, o := range operands {
+			args[i] = o.String()
+		}
+		return fmt.Sprintf("%v(%v)", ir.Opcode(), strings.Join(args, ", "))
+	}
+}
@@ -0,0 +1,120 @@
+package decompile
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"testing"
+)
+
+func TestDecompile(t *testing.T) {
+	tests := []struct {
+		name, program, expected string
+	}{
+		{
+			"loop",
+			`set a 3
+add b a
+add a -1
+jgz a -2`,
+			`func program() {
+	a = 3
+	for {
+		b += a
+		a += -1
+		if a <= 0 {
+			break
+		}
+	}
+}
+`,
+		},
+		{
+			"if else",
+			`jnz a 3
+set b 1
+jnz 1 2
+set b 2
+snd b`,
+			`func program() {
+	if a == 0 {
+		b = 1
+	} else {
+		b = 2
+	}
+	snd(b)
+}
+`,
+		},
+		{
+			"break, continue and return",
+			`add a 1
+jgz b 4
+jgz c -2
+jnz d 3
+jnz 1 -4
+jnz 1 10`,
+			`func program() {
+	for {
+		a += 1
+		if b > 0 {
+			break
+		}
+		if c > 0 {
+			continue
+		}
+		if d != 0 {
+			return
+		}
+	}
+	return
+}
+`,
+		},
+		{
+			"goto",
+			`jgz a 2
+set b 1
+set c 2
+jgz a -2`,
+			`func program() {
+	if a <= 0 {
+	L1:
+		b = 1
+	}
+	c = 2
+	if a > 0 {
+		goto L1
+	}
+}
+`,
+		},
+		{
+			"goto the end of an infinite loop",
+			`set a 1
+add a 1
+jnz 1 -1
+jgz a -1`,
+			`func program() {
+	a = 1
+L1:
+	for {
+		a += 1
+	}
+	if a > 0 {
+		goto L1
+	}
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		instructions, err := duet.ParseInstructions(test.program)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := Decompile(instructions); got != test.expected {
+			t.Errorf("%v: Decompile(...) =\n%v\nbut expected\n%v", test.name, got, test.expected)
+		}
+	}
+}
@@ -2,87 +2,106 @@ package duet
 
 import (
 	"fmt"
-	"github.com/pkg/errors"
 	"strings"
 )
 
 type Instruction interface {
-	operateOn(c *Controller) (shouldHalt bool)
+	fmt.Stringer
+	Opcode() string
+	Operands() []Operand
+	operateOn(c *Controller) status
 }
 
-func ParseInstructions(in string) (instructions []Instruction, err error) {
-	for _, line := range strings.Split(in, "\n") {
-		i, err := parseInstruction(line)
-		if err != nil {
-			return instructions, err
-		}
-		instructions = append(instructions, i)
+// format writes an instruction the way it is parsed
+func format(ir Instruction) string {
+	parts := []string{ir.Opcode()}
+	for _, o := range ir.Operands() {
+		parts = append(parts, o.String())
 	}
+	return strings.Join(parts, " ")
+}
+
+type set struct {
+	reg register
+	val value
+}
+
+func newSet(args []field) (s set, err error) {
+	s.reg, s.val, err = parseAsRegAndVal(args)
 	return
 }
 
-func parseInstruction(in string) (ir Instruction, err error) {
-	fields := strings.Fields(in)
+func (set) Opcode() string {
+	return "set"
+}
 
-	opcode := fields[0]
-	args := fields[1:]
+func (s set) Operands() []Operand {
+	return []Operand{s.reg.operand(), s.val.operand()}
+}
 
-	switch opcode {
-	case "snd":
-		return newSnd(args)
+func (s set) String() string {
+	return format(s)
+}
 
-	case "set":
-		return newSet(args)
+func (s set) operateOn(c *Controller) status {
+	if c.big {
+		c.bigRegisters[s.reg] = s.val.fetchBig(c)
+		return running
+	}
+	c.set(s.reg, s.val.fetch(c))
+	return running
+}
 
-	case "add":
-		return newAdd(args)
+type add struct {
+	reg register
+	val value
+}
 
-	case "mul":
-		return newMul(args)
+func newAdd(args []field) (a add, err error) {
+	a.reg, a.val, err = parseAsRegAndVal(args)
+	return
+}
 
-	case "mod":
-		return newMod(args)
+func (add) Opcode() string {
+	return "add"
+}
 
-	case "rcv":
-		return newRcv(args)
+func (a add) Operands() []Operand {
+	return []Operand{a.reg.operand(), a.val.operand()}
+}
 
-	case "jgz":
-		return newJgz(args)
+func (a add) String() string {
+	return format(a)
+}
 
-	default:
-		err = errors.New(fmt.Sprintf("could not recognize instruction %q", opcode))
-		return
-	}
+func (a add) operateOn(c *Controller) status {
+	return c.arithmetic(opAdd, a.reg, a.val)
 }
 
-type set struct {
+type sub struct {
 	reg register
 	val value
 }
 
-func newSet(args []string) (s set, err error) {
+func newSub(args []field) (s sub, err error) {
 	s.reg, s.val, err = parseAsRegAndVal(args)
 	return
 }
 
-func (s set) operateOn(c *Controller) (shouldHalt bool) {
-	c.set(s.reg, s.val.fetch(c))
-	return
+func (sub) Opcode() string {
+	return "sub"
 }
 
-type add struct {
-	reg register
-	val value
+func (s sub) Operands() []Operand {
+	return []Operand{s.reg.operand(), s.val.operand()}
 }
 
-func newAdd(args []string) (a add, err error) {
-	a.reg, a.val, err = parseAsRegAndVal(args)
-	return
+func (s sub) String() string {
+	return format(s)
 }
 
-func (a add) operateOn(c *Controller) (shouldHalt bool) {
-	c.set(a.reg, c.get(a.reg)+a.val.fetch(c))
-	return
+func (s sub) operateOn(c *Controller) status {
+	return c.arithmetic(opSub, s.reg, s.val)
 }
 
 type mul struct {
@@ -90,14 +109,25 @@ type mul struct {
 	val value
 }
 
-func newMul(args []string) (m mul, err error) {
+func newMul(args []field) (m mul, err error) {
 	m.reg, m.val, err = parseAsRegAndVal(args)
 	return
 }
 
-func (m mul) operateOn(c *Controller) (shouldHalt bool) {
-	c.set(m.reg, c.get(m.reg)*m.val.fetch(c))
-	return
+func (mul) Opcode() string {
+	return "mul"
+}
+
+func (m mul) Operands() []Operand {
+	return []Operand{m.reg.operand(), m.val.operand()}
+}
+
+func (m mul) String() string {
+	return format(m)
+}
+
+func (m mul) operateOn(c *Controller) status {
+	return c.arithmetic(opMul, m.reg, m.val)
 }
 
 type mod struct {
@@ -105,14 +135,25 @@ type mod struct {
 	val value
 }
 
-func newMod(args []string) (m mod, err error) {
+func newMod(args []field) (m mod, err error) {
 	m.reg, m.val, err = parseAsRegAndVal(args)
 	return
 }
 
-func (m mod) operateOn(c *Controller) (shouldHalt bool) {
-	c.set(m.reg, c.get(m.reg)%m.val.fetch(c))
-	return
+func (mod) Opcode() string {
+	return "mod"
+}
+
+func (m mod) Operands() []Operand {
+	return []Operand{m.reg.operand(), m.val.operand()}
+}
+
+func (m mod) String() string {
+	return format(m)
+}
+
+func (m mod) operateOn(c *Controller) status {
+	return c.arithmetic(opMod, m.reg, m.val)
 }
 
 type jgz struct {
@@ -120,64 +161,137 @@ type jgz struct {
 	offset value
 }
 
-func newJgz(args []string) (j jgz, err error) {
+func newJgz(args []field) (j jgz, err error) {
 	j.value, j.offset, err = parseAsValAndVal(args)
 	return
 }
 
-func (j jgz) operateOn(c *Controller) (shouldHalt bool) {
-	if j.value.fetch(c) > 0 {
-		c.jump(j.offset.fetch(c))
+func (jgz) Opcode() string {
+	return "jgz"
+}
+
+func (j jgz) Operands() []Operand {
+	return []Operand{j.value.operand(), j.offset.operand()}
+}
+
+func (j jgz) String() string {
+	return format(j)
+}
+
+func (j jgz) operateOn(c *Controller) status {
+	if c.sign(j.value) > 0 {
+		return c.jumpBy(opJgz, j.offset)
 	}
+	return running
+}
+
+type jnz struct {
+	value  value
+	offset value
+}
+
+func newJnz(args []field) (j jnz, err error) {
+	j.value, j.offset, err = parseAsValAndVal(args)
 	return
 }
 
+func (jnz) Opcode() string {
+	return "jnz"
+}
+
+func (j jnz) Operands() []Operand {
+	return []Operand{j.value.operand(), j.offset.operand()}
+}
+
+func (j jnz) String() string {
+	return format(j)
+}
+
+func (j jnz) operateOn(c *Controller) status {
+	if c.sign(j.value) != 0 {
+		return c.jumpBy(opJnz, j.offset)
+	}
+	return running
+}
+
 type snd struct {
 	val value
 }
 
-func newSnd(args []string) (s snd, err error) {
+func newSnd(args []field) (s snd, err error) {
 	s.val, err = parseAsVal(args)
 	return
 }
 
-func (s snd) operateOn(c *Controller) (shouldHalt bool) {
+func (snd) Opcode() string {
+	return "snd"
+}
+
+func (s snd) Operands() []Operand {
+	return []Operand{s.val.operand()}
+}
+
+func (s snd) String() string {
+	return format(s)
+}
+
+func (s snd) operateOn(c *Controller) status {
+	v, ok := c.fetchInt(s.val)
+	if !ok {
+		return c.fault(opSnd, Overflow)
+	}
+
 	switch c.version {
 	case v1:
-		c.playSound(s.val.fetch(c))
+		c.playSound(v)
 
 	case v2:
-		shouldHalt = !c.send(s.val.fetch(c))
+		return c.send(v)
 
+	default:
+		return c.unsupported(opSnd)
 	}
-	return
+	return running
 }
 
 type rcv struct {
 	reg register
 }
 
-func newRcv(args []string) (r rcv, err error) {
+func newRcv(args []field) (r rcv, err error) {
 	r.reg, err = parseAsReg(args)
 	return
 }
 
-func (r rcv) operateOn(c *Controller) (shouldHalt bool) {
+func (rcv) Opcode() string {
+	return "rcv"
+}
+
+func (r rcv) Operands() []Operand {
+	return []Operand{r.reg.operand()}
+}
+
+func (r rcv) String() string {
+	return format(r)
+}
+
+func (r rcv) operateOn(c *Controller) status {
 	switch c.version {
 	case v1:
-		if c.get(r.reg) != 0 {
-			shouldHalt = true
+		if c.sign(valueReg(r.reg)) != 0 {
+			return c.halt(Recovered)
 		}
 
 	case v2:
-		val, timedOut := c.recv()
-		if timedOut {
-			shouldHalt = true
-			break
+		val, ok := c.recv()
+		if !ok {
+			return blocked
 		}
 
 		c.set(r.reg, val)
 
+	default:
+		return c.unsupported(opRcv)
 	}
-	return
+	return running
 }
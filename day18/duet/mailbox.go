@@ -0,0 +1,62 @@
+package duet
+
+// OverflowPolicy decides what happens when a program sends to a mailbox that is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock makes the sender wait until the receiver has taken a value from the mailbox.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowHalt halts the sender, the controller reports SendFailed.
+	OverflowHalt
+)
+
+// Mailbox is a FIFO queue of values sent between programs. A capacity of 0 means the mailbox is unbounded.
+type Mailbox struct {
+	queue    []int
+	capacity int
+	policy   OverflowPolicy
+	// statistics
+	Sent          int
+	Received      int
+	HighWaterMark int
+	// number of sends that found the mailbox full, a blocked sender counts again on every retry
+	Overflows int
+}
+
+func NewMailbox() *Mailbox {
+	return &Mailbox{}
+}
+
+func NewBoundedMailbox(capacity int, policy OverflowPolicy) *Mailbox {
+	return &Mailbox{capacity: capacity, policy: policy}
+}
+
+func (m *Mailbox) Len() int {
+	return len(m.queue)
+}
+
+func (m *Mailbox) isFull() bool {
+	return m.capacity > 0 && len(m.queue) >= m.capacity
+}
+
+// push appends a value whether or not the mailbox is full, senders check isFull and count the overflow first
+func (m *Mailbox) push(value int) {
+	m.queue = append(m.queue, value)
+	m.Sent += 1
+
+	if len(m.queue) > m.HighWaterMark {
+		m.HighWaterMark = len(m.queue)
+	}
+}
+
+func (m *Mailbox) pop() (value int, ok bool) {
+	if len(m.queue) == 0 {
+		return 0, false
+	}
+
+	value = m.queue[0]
+	m.queue = m.queue[1:]
+	m.Received += 1
+
+	return value, true
+}
@@ -0,0 +1,53 @@
+package duet
+
+import "testing"
+
+func TestMailbox(t *testing.T) {
+	m := NewMailbox()
+
+	for i := 1; i <= 3; i++ {
+		m.push(i)
+	}
+	if m.isFull() {
+		t.Errorf("expected unbounded mailbox to never be full")
+	}
+
+	v, ok := m.pop()
+	if !ok || v != 1 {
+		t.Errorf("pop() = %v, %v, but expected 1, true", v, ok)
+	}
+
+	m.push(4)
+
+	for _, expected := range []int{2, 3, 4} {
+		v, ok = m.pop()
+		if !ok || v != expected {
+			t.Errorf("pop() = %v, %v, but expected %v, true", v, ok, expected)
+		}
+	}
+
+	_, ok = m.pop()
+	if ok {
+		t.Errorf("pop() on empty mailbox succeeded")
+	}
+
+	if m.Sent != 4 || m.Received != 4 || m.HighWaterMark != 3 {
+		t.Errorf("unexpected statistics, sent = %v, received = %v, high-water mark = %v", m.Sent, m.Received, m.HighWaterMark)
+	}
+}
+
+func TestMailbox_Bounded(t *testing.T) {
+	m := NewBoundedMailbox(2, OverflowHalt)
+
+	c := NewControllerV2(m, NewMailbox(), 0)
+	c.Run(parse(t, `snd 1
+snd 2
+snd 3`))
+
+	if c.HaltReason != SendFailed || c.ValuesSent != 2 {
+		t.Errorf("expected to send 2 values and halt with %v, but sent %v and halted with %v", SendFailed, c.ValuesSent, c.HaltReason)
+	}
+	if !m.isFull() || m.Len() != 2 || m.Overflows != 1 {
+		t.Errorf("expected 2 values and 1 overflow, but got %v values and %v overflows", m.Len(), m.Overflows)
+	}
+}
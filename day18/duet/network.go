@@ -0,0 +1,109 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+// Topology lists for every program the programs that receive the values it sends.
+type Topology [][]int
+
+// Ring sends the values of every program to the next one, the last program sends to the first.
+func Ring(n int) Topology {
+	t := make(Topology, n)
+	for i := range t {
+		t[i] = []int{(i + 1) % n}
+	}
+	return t
+}
+
+// Pairwise pairs up program 0 with 1, 2 with 3 and so on. With an odd amount of programs the last one sends to a
+// program that does not exist, which NewNetwork rejects.
+func Pairwise(n int) Topology {
+	t := make(Topology, n)
+	for i := range t {
+		t[i] = []int{i ^ 1}
+	}
+	return t
+}
+
+// Broadcast sends the values of every program to all other programs.
+func Broadcast(n int) Topology {
+	t := make(Topology, n)
+	for i := range t {
+		for j := 0; j < n; j++ {
+			if j != i {
+				t[i] = append(t[i], j)
+			}
+		}
+	}
+	return t
+}
+
+// Network runs a program on a controller per entry in its topology. Every controller has its program id in
+// register p and a single inbox, values are sent to the inboxes of all programs the topology lists.
+type Network struct {
+	Controllers []*Controller
+	Inboxes     []*Mailbox
+	scheduler   Scheduler
+}
+
+type ProgramStats struct {
+	ProgramId      int
+	Reason         HaltReason
+	Steps          int
+	ValuesSent     int
+	ValuesReceived int
+	HighWaterMark  int
+}
+
+func NewNetwork(instructions []Instruction, topology Topology, options ...Option) (n Network, err error) {
+	for i, destinations := range topology {
+		for _, d := range destinations {
+			if d < 0 || d >= len(topology) {
+				return n, errors.New(fmt.Sprintf("program %v sends to program %v, which does not exist", i, d))
+			}
+		}
+	}
+
+	for range topology {
+		n.Inboxes = append(n.Inboxes, NewMailbox())
+	}
+
+	for i, destinations := range topology {
+		var outboxes []*Mailbox
+		for _, d := range destinations {
+			outboxes = append(outboxes, n.Inboxes[d])
+		}
+
+		c := newControllerV2(outboxes, n.Inboxes[i], i, options)
+		n.Controllers = append(n.Controllers, &c)
+	}
+
+	n.scheduler = NewScheduler(instructions, n.Controllers...)
+	return
+}
+
+func (n *Network) Run() []ProgramStats {
+	results := n.scheduler.Run()
+
+	stats := make([]ProgramStats, len(results))
+	for i, result := range results {
+		c := n.Controllers[i]
+
+		steps := 0
+		for _, count := range c.OpcodeCounts {
+			steps += count
+		}
+
+		stats[i] = ProgramStats{
+			ProgramId:      result.ProgramId,
+			Reason:         result.Reason,
+			Steps:          steps,
+			ValuesSent:     c.ValuesSent,
+			ValuesReceived: n.Inboxes[i].Received,
+			HighWaterMark:  n.Inboxes[i].HighWaterMark,
+		}
+	}
+	return stats
+}
@@ -0,0 +1,85 @@
+package duet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopologies(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      Topology
+		expected Topology
+	}{
+		{"ring", Ring(3), Topology{{1}, {2}, {0}}},
+		{"pairwise", Pairwise(4), Topology{{1}, {0}, {3}, {2}}},
+		{"broadcast", Broadcast(3), Topology{{1, 2}, {0, 2}, {0, 1}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.expected) {
+			t.Errorf("%v topology = %v, but expected %v", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestNetwork_Ring(t *testing.T) {
+	// every program passes on what it receives, program 0 starts with its own id
+	instructions, err := ParseInstructions(`jgz p 2
+snd p
+rcv a
+add a 1
+snd a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewNetwork(instructions, Ring(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := n.Run()
+
+	expected := []ProgramStats{
+		{0, OutOfBounds, 5, 2, 1, 1},
+		{1, OutOfBounds, 4, 1, 1, 1},
+		{2, OutOfBounds, 4, 1, 1, 1},
+		{3, OutOfBounds, 4, 1, 1, 1},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Run() = %+v, but expected %+v", stats, expected)
+	}
+	if n.Controllers[0].Register("a") != 4 {
+		t.Errorf("expected program 0 to receive 3 and end with a = 4, but got %v", n.Controllers[0].Register("a"))
+	}
+}
+
+func TestNetwork_Broadcast(t *testing.T) {
+	instructions, err := ParseInstructions(`snd p
+rcv a
+rcv b
+rcv c`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewNetwork(instructions, Broadcast(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range n.Run() {
+		if s.Reason != Deadlock || s.ValuesSent != 1 || s.ValuesReceived != 2 {
+			t.Errorf("expected every program to send 1 and receive 2 values before deadlocking, but got %+v", s)
+		}
+	}
+}
+
+func TestNewNetwork_InvalidTopology(t *testing.T) {
+	if _, err := NewNetwork(nil, Topology{{1}, {2}}); err == nil {
+		t.Errorf("expected a topology referring to a missing program to be rejected")
+	}
+	if _, err := NewNetwork(nil, Pairwise(3)); err == nil {
+		t.Errorf("expected a pairwise topology of an odd amount of programs to be rejected")
+	}
+}
@@ -0,0 +1,326 @@
+package duet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rewrite describes a pattern the optimizer replaced by a fused instruction.
+type Rewrite struct {
+	Instruction int
+	Length      int
+	Pattern     string
+}
+
+func (r Rewrite) String() string {
+	return fmt.Sprintf("%v: %v, instructions %v to %v", r.Instruction, r.Pattern, r.Instruction, r.Instruction+r.Length-1)
+}
+
+// Optimize replaces loops that compute a multiplication, a divisibility check or a primality check by a single
+// fused instruction. A fused instruction takes the place of the first instruction of its loop and jumps over the
+// rest of it, all other instructions stay where they are so jump offsets remain valid. When the registers do not
+// satisfy the conditions a fused instruction relies on, it executes the original instruction instead, so register
+// results are always identical to the original program. Opcode counts are not.
+func Optimize(instructions []Instruction) (optimized []Instruction, rewrites []Rewrite) {
+	optimized = make([]Instruction, len(instructions))
+	copy(optimized, instructions)
+
+	for i := range instructions {
+		for _, p := range patterns {
+			b, ok := p.match(instructions[i:])
+			if !ok {
+				continue
+			}
+
+			optimized[i] = p.fuse(b, instructions[i])
+			rewrites = append(rewrites, Rewrite{i, len(p.lines), p.name})
+			break
+		}
+	}
+	return
+}
+
+// pattern is a sequence of instructions, every line can list alternatives separated by "|". In the operands, a
+// lowercase letter is any register, an uppercase letter is any constant, a name starting with "_" is either and
+// a number is that exact constant. Distinct lowercase letters must be distinct registers.
+type pattern struct {
+	name  string
+	lines []string
+	fuse  func(b bindings, original Instruction) Instruction
+}
+
+type bindings map[string]Operand
+
+func (p pattern) match(instructions []Instruction) (b bindings, ok bool) {
+	if len(instructions) < len(p.lines) {
+		return nil, false
+	}
+
+	b = make(bindings)
+
+	for i, line := range p.lines {
+		matched := false
+		for _, alternative := range strings.Split(line, "|") {
+			if b.matchInstruction(strings.Fields(alternative), instructions[i]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+	return b, true
+}
+
+func (b bindings) matchInstruction(fields []string, ir Instruction) bool {
+	operands := ir.Operands()
+
+	if fields[0] != ir.Opcode() || len(fields)-1 != len(operands) {
+		return false
+	}
+
+	// only commit the bindings of this alternative if all operands match
+	added := make(bindings)
+	for i, field := range fields[1:] {
+		if !b.matchOperand(field, operands[i], added) {
+			return false
+		}
+	}
+	for k, v := range added {
+		b[k] = v
+	}
+	return true
+}
+
+func (b bindings) matchOperand(field string, o Operand, added bindings) bool {
+	if n, err := strconv.Atoi(field); err == nil {
+		return !o.IsRegister() && o.Value == n
+	}
+
+	isReg := field[0] >= 'a' && field[0] <= 'z'
+	isConst := field[0] >= 'A' && field[0] <= 'Z'
+	if (isReg && !o.IsRegister()) || (isConst && o.IsRegister()) {
+		return false
+	}
+
+	if bound, ok := b[field]; ok {
+		return bound == o
+	}
+	if bound, ok := added[field]; ok {
+		return bound == o
+	}
+
+	if o.IsRegister() {
+		// a register can only be bound to one placeholder
+		for _, bs := range []bindings{b, added} {
+			for _, bound := range bs {
+				if bound == o {
+					return false
+				}
+			}
+		}
+	}
+
+	added[field] = o
+	return true
+}
+
+func (b bindings) reg(name string) register {
+	return register(b[name].Register)
+}
+
+func (b bindings) val(name string) value {
+	o := b[name]
+	if o.IsRegister() {
+		return valueReg(b.reg(name))
+	}
+	return valueInt(o.Value)
+}
+
+var patterns = []pattern{
+	{
+		name: "primality check",
+		lines: []string{
+			"set e K",
+			"set g d",
+			"mul g e",
+			"sub g b",
+			"jnz g 2",
+			"set f 0",
+			"sub e -1 | add e 1",
+			"set g e",
+			"sub g b",
+			"jnz g -8",
+			"sub d -1 | add d 1",
+			"set g d",
+			"sub g b",
+			"jnz g -13",
+		},
+		fuse: func(b bindings, original Instruction) Instruction {
+			return primality{b.reg("b"), b.reg("d"), b.reg("e"), b.reg("f"), b.reg("g"), b["K"].Value, original}
+		},
+	},
+	{
+		name: "divisibility check",
+		lines: []string{
+			"set g d",
+			"mul g e",
+			"sub g b",
+			"jnz g 2",
+			"set f 0",
+			"sub e -1 | add e 1",
+			"set g e",
+			"sub g b",
+			"jnz g -8",
+		},
+		fuse: func(b bindings, original Instruction) Instruction {
+			return divisibility{b.reg("b"), b.reg("d"), b.reg("e"), b.reg("f"), b.reg("g"), original}
+		},
+	},
+	multiplyPattern("add", 1, false),
+	multiplyPattern("add", 1, true),
+	multiplyPattern("sub", -1, false),
+	multiplyPattern("sub", -1, true),
+}
+
+// multiplyPattern matches a loop that adds x to a, c times. The counter can be decremented before or after the
+// addition.
+func multiplyPattern(opcode string, sign int, decrementFirst bool) pattern {
+	body := opcode + " a _x"
+	decrement := "add c -1 | sub c 1"
+
+	lines := []string{body, decrement, "jnz c -2 | jgz c -2"}
+	if decrementFirst {
+		lines[0], lines[1] = decrement, body
+	}
+
+	return pattern{
+		name:  "multiplication",
+		lines: lines,
+		fuse: func(b bindings, original Instruction) Instruction {
+			return multiply{b.reg("a"), b.reg("c"), b.val("_x"), sign, original}
+		},
+	}
+}
+
+// multiply is the fused form of a loop adding sign * x to a, c times.
+type multiply struct {
+	a, c     register
+	x        value
+	sign     int
+	original Instruction
+}
+
+func (multiply) Opcode() string {
+	return "multiply"
+}
+
+func (m multiply) Operands() []Operand {
+	return []Operand{m.a.operand(), m.c.operand(), m.x.operand()}
+}
+
+func (m multiply) String() string {
+	return format(m)
+}
+
+func (m multiply) operateOn(c *Controller) status {
+	n := c.get(m.c)
+	if c.big || n <= 0 {
+		return m.original.operateOn(c)
+	}
+
+	// the original loop overflows on the way only if the end result does, the other way around is not true
+	code := opAdd
+	if m.sign < 0 {
+		code = opSub
+	}
+	product, reason := calculate(opMul, m.x.fetch(c), n)
+	if reason != NotHalted {
+		return m.original.operateOn(c)
+	}
+	result, reason := calculate(code, c.get(m.a), product)
+	if reason != NotHalted {
+		return m.original.operateOn(c)
+	}
+
+	c.set(m.a, result)
+	c.set(m.c, 0)
+	c.jump(3)
+	return running
+}
+
+// divisibility is the fused form of a loop clearing f if d * e == b for any e in [e, b).
+type divisibility struct {
+	b, d, e, f, g register
+	original      Instruction
+}
+
+func (divisibility) Opcode() string {
+	return "divisibility"
+}
+
+func (p divisibility) Operands() []Operand {
+	return []Operand{p.b.operand(), p.d.operand(), p.e.operand(), p.f.operand(), p.g.operand()}
+}
+
+func (p divisibility) String() string {
+	return format(p)
+}
+
+func (p divisibility) operateOn(c *Controller) status {
+	b, d, e := c.get(p.b), c.get(p.d), c.get(p.e)
+
+	// d * e can not overflow and the loop ends when e reaches b
+	if c.big || d <= 0 || e <= 0 || e >= b || d > maxInt/b {
+		return p.original.operateOn(c)
+	}
+
+	if b%d == 0 && b/d >= e && b/d < b {
+		c.set(p.f, 0)
+	}
+	c.set(p.e, b)
+	c.set(p.g, 0)
+	c.jump(9)
+	return running
+}
+
+// primality is the fused form of two nested loops clearing f if d * e == b for any d in [d, b) and e in [k, b).
+type primality struct {
+	b, d, e, f, g register
+	k             int
+	original      Instruction
+}
+
+func (primality) Opcode() string {
+	return "primality"
+}
+
+func (p primality) Operands() []Operand {
+	return []Operand{p.b.operand(), p.d.operand(), p.e.operand(), p.f.operand(), p.g.operand(), valueInt(p.k).operand()}
+}
+
+func (p primality) String() string {
+	return format(p)
+}
+
+func (p primality) operateOn(c *Controller) status {
+	b, d := c.get(p.b), c.get(p.d)
+
+	if c.big || d <= 0 || d >= b || p.k <= 0 || p.k >= b || b > maxInt/b {
+		return p.original.operateOn(c)
+	}
+
+	for ; d < b && d <= b/p.k; d++ {
+		if b%d == 0 && b/d >= p.k && b/d < b {
+			c.set(p.f, 0)
+			break
+		}
+	}
+	c.set(p.d, b)
+	c.set(p.e, b)
+	c.set(p.g, 0)
+	c.jump(14)
+	return running
+}
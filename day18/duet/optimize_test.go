@@ -0,0 +1,84 @@
+package duet
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		values   []int
+		rewrites []Rewrite
+	}{
+		// a counter below 1 makes the fused multiplication fall back to the original loop
+		{"multiplication", `set b 7
+set c %v
+add a b
+add c -1
+jgz c -2
+set c 5
+sub c 1
+sub a 3
+jnz c -2`, []int{-3, 0, 1, 4}, []Rewrite{{2, 3, "multiplication"}, {6, 3, "multiplication"}}},
+		{"primality", `set b %v
+set f 1
+set d 2
+set e 2
+set g d
+mul g e
+sub g b
+jnz g 2
+set f 0
+sub e -1
+set g e
+sub g b
+jnz g -8
+sub d -1
+set g d
+sub g b
+jnz g -13`, []int{3, 4, 9, 17, 91}, []Rewrite{{3, 14, "primality check"}, {4, 9, "divisibility check"}}},
+	}
+
+	for _, tt := range tests {
+		for _, n := range tt.values {
+			t.Run(fmt.Sprintf("%v %v", tt.name, n), func(t *testing.T) {
+				instructions, err := ParseInstructions(fmt.Sprintf(tt.input, n))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				optimized, rewrites := Optimize(instructions)
+				if !reflect.DeepEqual(rewrites, tt.rewrites) {
+					t.Errorf("Optimize(...) rewrites = %v, but expected %v", rewrites, tt.rewrites)
+				}
+
+				expected := NewControllerV3()
+				expected.Run(instructions)
+
+				got := NewControllerV3()
+				got.Run(optimized)
+
+				if !reflect.DeepEqual(got.Registers(), expected.Registers()) {
+					t.Errorf("registers = %v, but expected %v", got.Registers(), expected.Registers())
+				}
+			})
+		}
+	}
+}
+
+func TestOptimize_NoMatch(t *testing.T) {
+	instructions, err := ParseInstructions(`add a b
+add b -1
+jnz b -2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rewrites := Optimize(instructions)
+	if len(rewrites) != 0 {
+		t.Errorf("expected no rewrites when the counter is also added, but got %v", rewrites)
+	}
+}
@@ -0,0 +1,116 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+type HaltReason uint8
+
+const (
+	NotHalted HaltReason = iota
+	OutOfBounds
+	Deadlock
+	SendFailed
+	Recovered
+	Overflow
+	DivisionByZero
+	Unsupported
+)
+
+func (r HaltReason) String() string {
+	switch r {
+	case NotHalted:
+		return "not halted"
+	case OutOfBounds:
+		return "out of bounds"
+	case Deadlock:
+		return "deadlock"
+	case SendFailed:
+		return "send failed"
+	case Recovered:
+		return "recovered"
+	case Overflow:
+		return "overflow"
+	case DivisionByZero:
+		return "division by zero"
+	case Unsupported:
+		return "unsupported"
+	default:
+		return "unknown"
+	}
+}
+
+func (r HaltReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *HaltReason) UnmarshalText(text []byte) error {
+	for reason := NotHalted; reason <= Unsupported; reason++ {
+		if reason.String() == string(text) {
+			*r = reason
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("unknown halt reason %q", text))
+}
+
+type Result struct {
+	ProgramId int
+	Reason    HaltReason
+}
+
+// Scheduler runs controllers that communicate with each other on a single goroutine. Each controller runs until it
+// halts or blocks on rcv or a full mailbox, the scheduler then moves on to the next one. When a whole round passes
+// without any controller executing an instruction, all remaining controllers are blocked: a deadlock.
+type Scheduler struct {
+	instructions []Instruction
+	controllers  []*Controller
+}
+
+func NewScheduler(instructions []Instruction, controllers ...*Controller) Scheduler {
+	return Scheduler{instructions, controllers}
+}
+
+func (s *Scheduler) Run() []Result {
+	for {
+		progress := false
+		active := 0
+
+		for _, c := range s.controllers {
+			if c.HaltReason != NotHalted {
+				continue
+			}
+
+			st := c.step(s.instructions)
+			for st == running {
+				progress = true
+				st = c.step(s.instructions)
+			}
+
+			if st == halted {
+				progress = true
+				continue
+			}
+			active += 1
+		}
+
+		if active == 0 {
+			break
+		}
+		if !progress {
+			for _, c := range s.controllers {
+				if c.HaltReason == NotHalted {
+					c.halt(Deadlock)
+				}
+			}
+			break
+		}
+	}
+
+	results := make([]Result, len(s.controllers))
+	for i, c := range s.controllers {
+		results[i] = Result{c.id, c.HaltReason}
+	}
+	return results
+}
@@ -0,0 +1,91 @@
+package duet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func runPair(t *testing.T, input string, mailbox0, mailbox1 *Mailbox) ([]Result, Controller, Controller) {
+	instructions, err := ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c0 := NewControllerV2(mailbox1, mailbox0, 0)
+	c1 := NewControllerV2(mailbox0, mailbox1, 1)
+
+	s := NewScheduler(instructions, &c0, &c1)
+	return s.Run(), c0, c1
+}
+
+func TestScheduler_Deadlock(t *testing.T) {
+	input := `snd 1
+snd 2
+snd p
+rcv a
+rcv b
+rcv c
+rcv d`
+
+	results, c0, c1 := runPair(t, input, NewMailbox(), NewMailbox())
+
+	expected := []Result{{0, Deadlock}, {1, Deadlock}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Run() = %v, but expected %v", results, expected)
+	}
+	if c0.ValuesSent != 3 || c1.ValuesSent != 3 {
+		t.Errorf("expected both programs to send 3 values, but got %v and %v", c0.ValuesSent, c1.ValuesSent)
+	}
+}
+
+func TestScheduler_OutOfBounds(t *testing.T) {
+	input := `snd p
+rcv a`
+
+	results, _, _ := runPair(t, input, NewMailbox(), NewMailbox())
+
+	expected := []Result{{0, OutOfBounds}, {1, OutOfBounds}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Run() = %v, but expected %v", results, expected)
+	}
+}
+
+func TestScheduler_SendFailed(t *testing.T) {
+	input := `snd 1
+snd 2
+rcv a`
+
+	results, _, _ := runPair(t, input, NewBoundedMailbox(1, OverflowHalt), NewBoundedMailbox(1, OverflowHalt))
+
+	expected := []Result{{0, SendFailed}, {1, SendFailed}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Run() = %v, but expected %v", results, expected)
+	}
+}
+
+func TestScheduler_BackPressure(t *testing.T) {
+	input := `jgz p 5
+snd 1
+snd 2
+snd 3
+rcv a
+rcv a
+rcv b
+rcv c`
+
+	mailbox0 := NewBoundedMailbox(1, OverflowBlock)
+	mailbox1 := NewBoundedMailbox(1, OverflowBlock)
+
+	results, c0, c1 := runPair(t, input, mailbox0, mailbox1)
+
+	expected := []Result{{0, Deadlock}, {1, OutOfBounds}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Run() = %v, but expected %v", results, expected)
+	}
+	if c0.ValuesSent != 3 || c1.get("c") != 3 {
+		t.Errorf("expected program 1 to receive 3 values, but got %v sent and %v received", c0.ValuesSent, c1.get("c"))
+	}
+	if mailbox1.HighWaterMark != 1 || mailbox1.Overflows == 0 {
+		t.Errorf("expected mailbox to be full and overflow, but got high-water mark %v and %v overflows", mailbox1.HighWaterMark, mailbox1.Overflows)
+	}
+}
@@ -0,0 +1,192 @@
+package duet
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// Snapshot is the serializable state of a group of controllers and the mailboxes they share.
+type Snapshot struct {
+	Controllers []ControllerSnapshot `json:"controllers"`
+	Mailboxes   []MailboxSnapshot    `json:"mailboxes,omitempty"`
+}
+
+// ControllerSnapshot refers to its mailboxes by their index in Snapshot.Mailboxes, Inbox is -1 if it has none.
+type ControllerSnapshot struct {
+	Version   int            `json:"version"`
+	ProgramId int            `json:"programId"`
+	Registers map[string]int `json:"registers,omitempty"`
+	// whether the controller is in big int mode, BigRegisters has the decimal values of its registers
+	BigInt        bool              `json:"bigInt,omitempty"`
+	BigRegisters  map[string]string `json:"bigRegisters,omitempty"`
+	IrPointer     int               `json:"irPointer"`
+	HaltReason    HaltReason        `json:"haltReason"`
+	OpcodeCounts  map[string]int    `json:"opcodeCounts"`
+	LastFrequency int               `json:"lastFrequency"`
+	ValuesSent    int               `json:"valuesSent"`
+	Outboxes      []int             `json:"outboxes,omitempty"`
+	Inbox         int               `json:"inbox"`
+}
+
+type MailboxSnapshot struct {
+	Queue         []int          `json:"queue"`
+	Capacity      int            `json:"capacity"`
+	Policy        OverflowPolicy `json:"policy"`
+	Sent          int            `json:"sent"`
+	Received      int            `json:"received"`
+	HighWaterMark int            `json:"highWaterMark"`
+	Overflows     int            `json:"overflows"`
+}
+
+func TakeSnapshot(controllers ...*Controller) (s Snapshot) {
+	mailboxes := make(map[*Mailbox]int)
+
+	indexOf := func(m *Mailbox) int {
+		if m == nil {
+			return -1
+		}
+		i, ok := mailboxes[m]
+		if !ok {
+			i = len(s.Mailboxes)
+			mailboxes[m] = i
+			s.Mailboxes = append(s.Mailboxes, m.snapshot())
+		}
+		return i
+	}
+
+	for _, c := range controllers {
+		opcodeCounts := make(map[string]int, len(c.OpcodeCounts))
+		for k, v := range c.OpcodeCounts {
+			opcodeCounts[k] = v
+		}
+
+		var outboxes []int
+		for _, m := range c.outboxes {
+			outboxes = append(outboxes, indexOf(m))
+		}
+
+		cs := ControllerSnapshot{
+			Version:       int(c.version) + 1,
+			ProgramId:     c.id,
+			IrPointer:     c.irPointer,
+			HaltReason:    c.HaltReason,
+			OpcodeCounts:  opcodeCounts,
+			LastFrequency: c.LastFrequency,
+			ValuesSent:    c.ValuesSent,
+			Outboxes:      outboxes,
+			Inbox:         indexOf(c.inbox),
+		}
+
+		if c.big {
+			cs.BigInt = true
+			cs.BigRegisters = make(map[string]string)
+			for name, v := range c.BigRegisters() {
+				cs.BigRegisters[name] = v.String()
+			}
+		} else {
+			cs.Registers = c.Registers()
+		}
+
+		s.Controllers = append(s.Controllers, cs)
+	}
+	return
+}
+
+func (m *Mailbox) snapshot() MailboxSnapshot {
+	queue := make([]int, len(m.queue))
+	copy(queue, m.queue)
+
+	return MailboxSnapshot{queue, m.capacity, m.policy, m.Sent, m.Received, m.HighWaterMark, m.Overflows}
+}
+
+func (s MailboxSnapshot) restore() *Mailbox {
+	queue := make([]int, len(s.Queue))
+	copy(queue, s.Queue)
+
+	return &Mailbox{queue, s.Capacity, s.Policy, s.Sent, s.Received, s.HighWaterMark, s.Overflows}
+}
+
+// Restore recreates the controllers in the snapshot, in the same order. Controllers that shared a mailbox share
+// the restored mailbox. The options are applied to every controller.
+func (s Snapshot) Restore(options ...Option) (controllers []Controller, err error) {
+	mailboxes := make([]*Mailbox, len(s.Mailboxes))
+	for i, m := range s.Mailboxes {
+		mailboxes[i] = m.restore()
+	}
+
+	mailbox := func(i int) (*Mailbox, error) {
+		if i == -1 {
+			return nil, nil
+		}
+		if i < 0 || i >= len(mailboxes) {
+			return nil, errors.New(fmt.Sprintf("mailbox %v does not exist", i))
+		}
+		return mailboxes[i], nil
+	}
+
+	for i, cs := range s.Controllers {
+		var c Controller
+
+		if cs.Version < 1 || cs.Version > 3 {
+			return nil, errors.New(fmt.Sprintf("controller %v: unknown version %v", i, cs.Version))
+		}
+		c.version = controllerVersion(cs.Version - 1)
+		c.id = cs.ProgramId
+
+		for _, o := range cs.Outboxes {
+			m, err := mailbox(o)
+			if err != nil || m == nil {
+				return nil, errors.New(fmt.Sprintf("controller %v: outbox %v does not exist", i, o))
+			}
+			c.outboxes = append(c.outboxes, m)
+		}
+		if c.inbox, err = mailbox(cs.Inbox); err != nil {
+			return nil, errors.Wrapf(err, "controller %v", i)
+		}
+		if c.version == v2 && c.inbox == nil {
+			return nil, errors.New(fmt.Sprintf("controller %v: version 2 needs an inbox", i))
+		}
+
+		if !cs.BigInt && cs.BigRegisters != nil {
+			return nil, errors.New(fmt.Sprintf("controller %v: big registers without big int mode", i))
+		}
+
+		c.registers = make(map[register]int, len(cs.Registers))
+		for name, value := range cs.Registers {
+			r, err := asReg(name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "controller %v", i)
+			}
+			c.set(r, value)
+		}
+
+		if cs.BigInt {
+			WithBigInt()(&c)
+			for name, value := range cs.BigRegisters {
+				r, err := asReg(name)
+				if err != nil {
+					return nil, errors.Wrapf(err, "controller %v", i)
+				}
+				v, ok := new(big.Int).SetString(value, 10)
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("controller %v: could not parse value %q of register %v", i, value, name))
+				}
+				c.bigRegisters[r] = v
+			}
+		}
+
+		c.apply(options)
+
+		c.irPointer = cs.IrPointer
+		c.HaltReason = cs.HaltReason
+		for k, v := range cs.OpcodeCounts {
+			c.OpcodeCounts[k] = v
+		}
+		c.LastFrequency = cs.LastFrequency
+		c.ValuesSent = cs.ValuesSent
+
+		controllers = append(controllers, c)
+	}
+	return
+}
@@ -0,0 +1,142 @@
+package duet
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func roundTrip(t *testing.T, s Snapshot) Snapshot {
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restored Snapshot
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	return restored
+}
+
+func TestSnapshot_V1(t *testing.T) {
+	instructions, err := ParseInstructions(`set a 1
+add a 2
+mul a a
+mod a 5
+snd a
+set a 0
+rcv a
+jgz a -1
+set a 1
+jgz a -2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewControllerV1()
+	expected.Run(instructions)
+
+	c := NewControllerV1()
+	for i := 0; i < 6; i++ {
+		c.step(instructions)
+	}
+
+	restored, err := roundTrip(t, TakeSnapshot(&c)).Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := restored[0]
+	got.Run(instructions)
+
+	if got.LastFrequency != expected.LastFrequency || got.HaltReason != expected.HaltReason {
+		t.Errorf("restored controller halted with %v and frequency %v, but expected %v and %v", got.HaltReason, got.LastFrequency, expected.HaltReason, expected.LastFrequency)
+	}
+	if !reflect.DeepEqual(got.Registers(), expected.Registers()) || !reflect.DeepEqual(got.OpcodeCounts, expected.OpcodeCounts) {
+		t.Errorf("restored controller has registers %v and counts %v, but expected %v and %v", got.Registers(), got.OpcodeCounts, expected.Registers(), expected.OpcodeCounts)
+	}
+}
+
+func TestSnapshot_BigIntWithoutRegisters(t *testing.T) {
+	instructions, err := ParseInstructions(`set a 9223372036854775807
+add a 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewControllerV1(WithBigInt())
+	expected.Run(instructions)
+
+	c := NewControllerV1(WithBigInt())
+	restored, err := roundTrip(t, TakeSnapshot(&c)).Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := restored[0]
+	got.Run(instructions)
+
+	if got.HaltReason != expected.HaltReason || got.BigRegister("a").Cmp(expected.BigRegister("a")) != 0 {
+		t.Errorf("restored controller halted with %v and a = %v, but expected %v and %v", got.HaltReason, got.BigRegister("a"), expected.HaltReason, expected.BigRegister("a"))
+	}
+}
+
+func TestSnapshot_V2(t *testing.T) {
+	instructions, err := ParseInstructions(`snd 1
+snd 2
+snd p
+rcv a
+rcv b
+rcv c
+rcv d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mailbox0, mailbox1 := NewMailbox(), NewMailbox()
+	c0 := NewControllerV2(mailbox1, mailbox0, 0)
+	c1 := NewControllerV2(mailbox0, mailbox1, 1)
+
+	// interrupt with values pending in both mailboxes
+	for i := 0; i < 3; i++ {
+		c0.step(instructions)
+	}
+	for i := 0; i < 4; i++ {
+		c1.step(instructions)
+	}
+
+	snapshot := roundTrip(t, TakeSnapshot(&c0, &c1))
+	if len(snapshot.Mailboxes) != 2 || !reflect.DeepEqual(snapshot.Mailboxes[0].Queue, []int{2, 0}) {
+		t.Errorf("expected two shared mailboxes with pending values, but got %+v", snapshot.Mailboxes)
+	}
+
+	restored, err := snapshot.Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScheduler(instructions, &restored[0], &restored[1])
+	results := s.Run()
+
+	expected := []Result{{0, Deadlock}, {1, Deadlock}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Run() = %v, but expected %v", results, expected)
+	}
+
+	expectedRegisters := []map[string]int{
+		{"a": 1, "b": 2, "c": 1, "p": 0},
+		{"a": 1, "b": 2, "c": 0, "p": 1},
+	}
+	for i, c := range restored {
+		if !reflect.DeepEqual(c.Registers(), expectedRegisters[i]) || c.ValuesSent != 3 {
+			t.Errorf("program %v has registers %v and sent %v, but expected %v and 3", i, c.Registers(), c.ValuesSent, expectedRegisters[i])
+		}
+	}
+}
+
+func TestSnapshot_RestoreInvalid(t *testing.T) {
+	s := Snapshot{Controllers: []ControllerSnapshot{{Version: 2, Outboxes: []int{0}, Inbox: -1}}}
+
+	if _, err := s.Restore(); err == nil {
+		t.Errorf("expected restoring a controller with missing mailboxes to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package duet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Tracer is notified of everything a controller does while running. OnStep is called once an instruction has
+// executed, after the events it caused, so an instruction that blocks is only reported when it is retried and runs.
+type Tracer interface {
+	OnStep(programId, irPointer int, ir Instruction)
+	OnSend(programId, value int)
+	OnRecv(programId, value int)
+	OnHalt(programId int, reason HaltReason)
+}
+
+func WithTracer(t Tracer) Option {
+	return func(c *Controller) {
+		c.tracer = t
+	}
+}
+
+type NopTracer struct{}
+
+func (NopTracer) OnStep(programId, irPointer int, ir Instruction) {}
+func (NopTracer) OnSend(programId, value int)                     {}
+func (NopTracer) OnRecv(programId, value int)                     {}
+func (NopTracer) OnHalt(programId int, reason HaltReason)         {}
+
+// TextTracer writes a human-readable line per event.
+type TextTracer struct {
+	w io.Writer
+}
+
+func NewTextTracer(w io.Writer) TextTracer {
+	return TextTracer{w}
+}
+
+func (t TextTracer) OnStep(programId, irPointer int, ir Instruction) {
+	fmt.Fprintf(t.w, "%v | %v | %v\n", programId, irPointer, ir)
+}
+
+func (t TextTracer) OnSend(programId, value int) {
+	fmt.Fprintf(t.w, "%v | sent %v\n", programId, value)
+}
+
+func (t TextTracer) OnRecv(programId, value int) {
+	fmt.Fprintf(t.w, "%v | received %v\n", programId, value)
+}
+
+func (t TextTracer) OnHalt(programId int, reason HaltReason) {
+	fmt.Fprintf(t.w, "%v | done: %v\n", programId, reason)
+}
+
+// JSONTracer writes every event as a JSON object on its own line.
+type JSONTracer struct {
+	enc *json.Encoder
+}
+
+type traceEvent struct {
+	Event       string `json:"event"`
+	Program     int    `json:"program"`
+	IrPointer   *int   `json:"irPointer,omitempty"`
+	Instruction string `json:"instruction,omitempty"`
+	Value       *int   `json:"value,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func NewJSONTracer(w io.Writer) JSONTracer {
+	return JSONTracer{json.NewEncoder(w)}
+}
+
+func (t JSONTracer) OnStep(programId, irPointer int, ir Instruction) {
+	t.enc.Encode(traceEvent{Event: "step", Program: programId, IrPointer: &irPointer, Instruction: ir.String()})
+}
+
+func (t JSONTracer) OnSend(programId, value int) {
+	t.enc.Encode(traceEvent{Event: "send", Program: programId, Value: &value})
+}
+
+func (t JSONTracer) OnRecv(programId, value int) {
+	t.enc.Encode(traceEvent{Event: "recv", Program: programId, Value: &value})
+}
+
+func (t JSONTracer) OnHalt(programId int, reason HaltReason) {
+	t.enc.Encode(traceEvent{Event: "halt", Program: programId, Reason: reason.String()})
+}
@@ -0,0 +1,75 @@
+package duet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONTracer(t *testing.T) {
+	instructions, err := ParseInstructions(`snd 5
+rcv a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	mailbox := NewMailbox()
+	c := NewControllerV2(mailbox, mailbox, 3, WithTracer(NewJSONTracer(&buf)))
+	c.Run(instructions)
+
+	expected := `{"event":"send","program":3,"value":5}
+{"event":"step","program":3,"irPointer":0,"instruction":"snd 5"}
+{"event":"recv","program":3,"value":5}
+{"event":"step","program":3,"irPointer":1,"instruction":"rcv a"}
+{"event":"halt","program":3,"reason":"out of bounds"}
+`
+	if buf.String() != expected {
+		t.Errorf("JSONTracer wrote\n%v\nbut expected\n%v", buf.String(), expected)
+	}
+}
+
+func TestTextTracer(t *testing.T) {
+	instructions, err := ParseInstructions(`snd 5
+rcv a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	c := NewControllerV1(WithTracer(NewTextTracer(&buf)))
+	c.Run(instructions)
+
+	expected := `0 | 0 | snd 5
+0 | 1 | rcv a
+0 | done: out of bounds
+`
+	if buf.String() != expected {
+		t.Errorf("TextTracer wrote\n%v\nbut expected\n%v", buf.String(), expected)
+	}
+}
+
+func TestTracer_Blocked(t *testing.T) {
+	// program 0 blocks on rcv until program 1 sends
+	instructions, err := ParseInstructions(`jgz p 3
+rcv a
+jgz 1 2
+snd 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	n, err := NewNetwork(instructions, Pairwise(2), WithTracer(NewTextTracer(&buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Run()
+
+	if steps := strings.Count(buf.String(), "0 | 1 | rcv a"); steps != 1 || n.Controllers[0].OpcodeCounts["rcv"] != 1 {
+		t.Errorf("expected rcv of program 0 to be traced and counted once, but got\n%v", buf.String())
+	}
+}
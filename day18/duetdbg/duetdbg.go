@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const help = `commands:
+  s, step [n]           execute n instructions (default 1)
+  c, continue           run until a breakpoint or watchpoint is hit
+  b, break <ip>         set a breakpoint on instruction ip
+  d, delete <ip>        remove the breakpoint on instruction ip
+  w, watch <reg> [val]  stop when reg changes, or when it reaches val
+  u, unwatch <reg>      remove the watchpoints on reg
+  r, regs               print all registers
+  l, list               print the program
+  q, quit               exit the debugger`
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: duetdbg <program file>")
+		os.Exit(2)
+	}
+
+	in, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+
+	instructions, err := duet.ParseInstructions(string(in))
+	if err != nil {
+		panic(err)
+	}
+
+	c := duet.NewControllerV1()
+	d := duet.NewDebugger(&c, instructions)
+
+	repl(&d, os.Stdin, os.Stdout)
+}
+
+func repl(d *duet.Debugger, in io.Reader, out io.Writer) {
+	scanner := bufio.NewScanner(in)
+
+	fmt.Fprint(out, "(duet) ")
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) > 0 {
+			if fields[0] == "q" || fields[0] == "quit" {
+				return
+			}
+			err := execute(d, fields[0], fields[1:], out)
+			if err != nil {
+				fmt.Fprintln(out, err)
+			}
+		}
+
+		fmt.Fprint(out, "(duet) ")
+	}
+}
+
+func execute(d *duet.Debugger, command string, args []string, out io.Writer) error {
+	switch command {
+	case "s", "step":
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				return errors.New(fmt.Sprintf("could not parse step count %q", args[0]))
+			}
+		}
+		stop := duet.Stop{Reason: duet.Stepped}
+		for i := 0; i < n && stop.Reason == duet.Stepped; i++ {
+			stop = d.Step()
+		}
+		printStop(d, stop, out)
+
+	case "c", "continue":
+		printStop(d, d.Continue(), out)
+
+	case "b", "break":
+		ip, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		return d.AddBreakpoint(ip)
+
+	case "d", "delete":
+		ip, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		d.RemoveBreakpoint(ip)
+
+	case "w", "watch":
+		if len(args) == 0 {
+			for _, w := range d.Watchpoints() {
+				fmt.Fprintln(out, w)
+			}
+			return nil
+		}
+		w := duet.Watchpoint{Register: args[0], Kind: duet.WatchChange}
+		if len(args) > 1 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(fmt.Sprintf("could not parse value %q", args[1]))
+			}
+			w.Kind = duet.WatchValue
+			w.Value = v
+		}
+		return d.AddWatchpoint(w)
+
+	case "u", "unwatch":
+		if len(args) != 1 {
+			return errors.New("expecting a register")
+		}
+		d.RemoveWatchpoint(args[0])
+
+	case "r", "regs":
+		printRegisters(d.Controller(), out)
+
+	case "l", "list":
+		printListing(d, out)
+
+	default:
+		fmt.Fprintln(out, help)
+	}
+	return nil
+}
+
+func intArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expecting an instruction index")
+	}
+	i, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("could not parse instruction index %q", args[0]))
+	}
+	return i, nil
+}
+
+func printStop(d *duet.Debugger, stop duet.Stop, out io.Writer) {
+	switch stop.Reason {
+	case duet.AtWatchpoint:
+		fmt.Fprintf(out, "watchpoint %v: %v -> %v\n", stop.Watchpoint, stop.OldValue, stop.NewValue)
+	case duet.Halted:
+		fmt.Fprintf(out, "halted: %v\n", d.Controller().HaltReason)
+		return
+	case duet.Blocked:
+		fmt.Fprintln(out, "blocked")
+	}
+	printInstruction(d, stop.IrPointer, out)
+}
+
+func printInstruction(d *duet.Debugger, ip int, out io.Writer) {
+	marker := " "
+	if ip == d.Controller().IrPointer() {
+		marker = ">"
+	}
+	for _, b := range d.Breakpoints() {
+		if b == ip {
+			marker += "*"
+		}
+	}
+	if ip < 0 || ip >= len(d.Instructions()) {
+		fmt.Fprintf(out, "%-2v %3v  end of program\n", marker, ip)
+		return
+	}
+	fmt.Fprintf(out, "%-2v %3v  %v\n", marker, ip, d.Instructions()[ip])
+}
+
+func printListing(d *duet.Debugger, out io.Writer) {
+	for ip := range d.Instructions() {
+		printInstruction(d, ip, out)
+	}
+}
+
+func printRegisters(c *duet.Controller, out io.Writer) {
+	registers := c.Registers()
+
+	var names []string
+	for name := range registers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "%v = %v\n", name, registers[name])
+	}
+}
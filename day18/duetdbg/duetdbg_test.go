@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"strings"
+	"testing"
+)
+
+func Test_repl(t *testing.T) {
+	instructions, err := duet.ParseInstructions(`set a 3
+add a -1
+jgz a -1
+rcv a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := duet.NewControllerV1()
+	d := duet.NewDebugger(&c, instructions)
+
+	in := strings.NewReader(`watch a 1
+continue
+regs
+break 3
+c
+quit
+step`)
+	var out bytes.Buffer
+
+	repl(&d, in, &out)
+
+	expected := `(duet) (duet) watchpoint a == 1: 2 -> 1
+>    2  jgz a -1
+(duet) a = 1
+(duet) (duet) >*   3  rcv a
+(duet) `
+	if out.String() != expected {
+		t.Errorf("repl(...) wrote\n%v\nbut expected\n%v", out.String(), expected)
+	}
+}
+
+func Test_repl_EndOfProgram(t *testing.T) {
+	instructions, err := duet.ParseInstructions(`set a 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := duet.NewControllerV1()
+	d := duet.NewDebugger(&c, instructions)
+
+	in := strings.NewReader(`step
+step`)
+	var out bytes.Buffer
+
+	repl(&d, in, &out)
+
+	expected := `(duet) >    1  end of program
+(duet) halted: out of bounds
+(duet) `
+	if out.String() != expected {
+		t.Errorf("repl(...) wrote\n%v\nbut expected\n%v", out.String(), expected)
+	}
+}
+
+func Test_repl_WatchpointOnLastInstruction(t *testing.T) {
+	instructions, err := duet.ParseInstructions(`set a 1
+add a 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := duet.NewControllerV1()
+	d := duet.NewDebugger(&c, instructions)
+
+	in := strings.NewReader(`watch a 2
+continue`)
+	var out bytes.Buffer
+
+	repl(&d, in, &out)
+
+	expected := `(duet) (duet) watchpoint a == 2: 1 -> 2
+>    2  end of program
+(duet) `
+	if out.String() != expected {
+		t.Errorf("repl(...) wrote\n%v\nbut expected\n%v", out.String(), expected)
+	}
+}
+
+func Test_repl_BreakpointOnFirstInstruction(t *testing.T) {
+	instructions, err := duet.ParseInstructions(`set a 1
+add a 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := duet.NewControllerV1()
+	d := duet.NewDebugger(&c, instructions)
+
+	in := strings.NewReader(`b 0
+c
+c`)
+	var out bytes.Buffer
+
+	repl(&d, in, &out)
+
+	expected := `(duet) (duet) >*   0  set a 1
+(duet) halted: out of bounds
+(duet) `
+	if out.String() != expected {
+		t.Errorf("repl(...) wrote\n%v\nbut expected\n%v", out.String(), expected)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet/decompile"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: duetdecompile <program file>")
+		os.Exit(2)
+	}
+
+	in, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+
+	instructions, err := duet.ParseInstructions(string(in))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(decompile.Decompile(instructions))
+}
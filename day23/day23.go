@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+)
+
+var printRewrites = flag.Bool("rewrites", false, "print the rewrites applied by the optimizer")
+
+func main() {
+	flag.Parse()
+
+	fmt.Println("Advent of Code 2017 - day 23")
+
+	instructions, err := duet.ParseInstructions(input)
+	if err != nil {
+		panic(err)
+	}
+
+	mulInvocations, registers := countMulInvocations(instructions)
+	fmt.Printf("Puzzle 1: amount of times mul is invoked = %v\n", mulInvocations)
+	fmt.Printf("          final registers = %v\n", registers)
+
+	optimized, rewrites := duet.Optimize(instructions)
+	if *printRewrites {
+		for _, r := range rewrites {
+			fmt.Printf("rewrite %v\n", r)
+		}
+	}
+
+	h := valueOfHInDebugMode(optimized)
+	fmt.Printf("Puzzle 2: value left in register h with the debug switch off = %v\n", h)
+}
+
+func countMulInvocations(instructions []duet.Instruction) (int, map[string]int) {
+	c := duet.NewControllerV3()
+	c.Run(instructions)
+	return c.OpcodeCounts["mul"], c.Registers()
+}
+
+func valueOfHInDebugMode(instructions []duet.Instruction) int {
+	debug, err := duet.WithRegister("a", 1)
+	if err != nil {
+		panic(err)
+	}
+
+	c := duet.NewControllerV3(debug)
+	c.Run(instructions)
+	return c.Register("h")
+}
+
+const input = `set b 57
+set c b
+jnz a 2
+jnz 1 5
+mul b 100
+sub b -100000
+set c b
+sub c -17000
+set f 1
+set d 2
+set e 2
+set g d
+mul g e
+sub g b
+jnz g 2
+set f 0
+sub e -1
+set g e
+sub g b
+jnz g -8
+sub d -1
+set g d
+sub g b
+jnz g -13
+jnz f 2
+sub h -1
+set g b
+sub g c
+jnz g 2
+jnz 1 3
+sub b -17
+jnz 1 -23`
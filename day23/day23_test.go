@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/koenaad/Advent-of-Code-2017/day18/duet"
+	"testing"
+)
+
+func Test_countMulInvocations(t *testing.T) {
+	input := `set a 3
+set b 2
+mul b 2
+sub a 1
+jnz a -2
+mul a 5`
+	instructions, err := duet.ParseInstructions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := 4
+
+	got, registers := countMulInvocations(instructions)
+	if got != expected {
+		t.Errorf("countMulInvocations(...) = %v, but expected %v", got, expected)
+	}
+	if registers["b"] != 16 {
+		t.Errorf("expected b = 16, but got %v", registers["b"])
+	}
+}
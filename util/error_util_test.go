@@ -0,0 +1,18 @@
+package util
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestLineErrors_Error(t *testing.T) {
+	err := LineErrors{
+		{2, errors.New("first")},
+		{5, errors.New("second")},
+	}
+
+	expected := "line 2: first\nline 5: second"
+	if err.Error() != expected {
+		t.Errorf("Error() = %q, but expected %q", err.Error(), expected)
+	}
+}